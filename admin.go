@@ -0,0 +1,349 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	protoc "github.com/YONEDASH/beacon/internal/protoc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventInfo describes the handlers subscribed to an event name or pattern.
+type EventInfo struct {
+	// Name is the event name, or the pattern for pattern subscriptions.
+	Name     string
+	Pattern  bool
+	Handlers int
+}
+
+// Events returns the event names and patterns with subscribed handlers, sorted by name.
+func (s *Engine) Events() []EventInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]EventInfo, 0, len(s.handlers))
+	for name, subs := range s.handlers {
+		if len(subs) > 0 {
+			events = append(events, EventInfo{Name: name, Handlers: len(subs)})
+		}
+	}
+
+	patterns := make(map[string]int)
+	for _, sub := range s.patterns {
+		patterns[sub.pattern]++
+	}
+	for pattern, handlers := range patterns {
+		events = append(events, EventInfo{Name: pattern, Pattern: true, Handlers: handlers})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Name != events[j].Name {
+			return events[i].Name < events[j].Name
+		}
+		return !events[i].Pattern
+	})
+	return events
+}
+
+type adminServer struct {
+	protoc.UnimplementedAdminServiceServer
+	engine  *Engine
+	service *server
+}
+
+// RegisterAdminService registers a service letting operators inspect and control an engine:
+// list subscriptions, read statistics, pause and resume events and replay dead letters.
+//
+// Callers are authenticated and authorized with the WithAuthenticator and WithAuthorizer
+// options like clients submitting an event named "admin." followed by the RPC name, for
+// example "admin.PauseEvents". The event has the time of the call as its timestamp and the
+// serialized request as its data, so HMAC signatures expire with the allowed skew and cannot
+// be reused for other requests. Without an authenticator every call is rejected with
+// ErrUnauthenticated.
+func RegisterAdminService(s *grpc.Server, engine *Engine, opts ...ServiceOption) {
+	protoc.RegisterAdminServiceServer(s, &adminServer{engine: engine, service: newServer(engine, opts...)})
+}
+
+// admit authenticates and authorizes a call of an admin RPC with the request.
+func (s *adminServer) admit(ctx context.Context, rpc string, req proto.Message) error {
+	if s.service.authenticator == nil {
+		return toStatus(fmt.Errorf("%w: admin service requires an authenticator", ErrUnauthenticated))
+	}
+	data, err := marshalAdminRequest(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	in := incomingEvent(ctx, &IncomingEvent{Name: "admin." + rpc, Data: data})
+	// Calls without a valid timestamp keep the zero time, which fails a skew check
+	in.Timestamp, _ = time.Parse(time.RFC3339Nano, in.Header.Get(timestampHeader))
+	if _, err := s.service.admit(ctx, in); err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// marshalAdminRequest serializes an admin request for signing. Deterministic encoding makes
+// the client and the server sign the same bytes.
+func marshalAdminRequest(req proto.Message) ([]byte, error) {
+	return proto.MarshalOptions{Deterministic: true}.Marshal(req)
+}
+
+func (s *adminServer) ListEvents(ctx context.Context, req *protoc.ListEventsRequest) (*protoc.ListEventsResponse, error) {
+	if err := s.admit(ctx, "ListEvents", req); err != nil {
+		return nil, err
+	}
+	resp := &protoc.ListEventsResponse{}
+	for _, info := range s.engine.Events() {
+		resp.Events = append(resp.Events, &protoc.EventInfo{
+			Name:     info.Name,
+			Pattern:  info.Pattern,
+			Handlers: int32(info.Handlers),
+		})
+	}
+	return resp, nil
+}
+
+func (s *adminServer) GetStats(ctx context.Context, req *protoc.GetStatsRequest) (*protoc.GetStatsResponse, error) {
+	if err := s.admit(ctx, "GetStats", req); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(req.EventNames))
+	for _, name := range req.EventNames {
+		names[name] = true
+	}
+
+	resp := &protoc.GetStatsResponse{}
+	for _, st := range s.engine.Stats() {
+		if len(names) > 0 && !names[st.Name] {
+			continue
+		}
+		resp.Stats = append(resp.Stats, &protoc.EventStats{
+			Name:         st.Name,
+			Dispatched:   st.Dispatched,
+			Failed:       st.Failed,
+			Canceled:     st.Canceled,
+			TotalLatency: durationpb.New(st.TotalLatency),
+			MaxLatency:   durationpb.New(st.MaxLatency),
+		})
+	}
+	return resp, nil
+}
+
+func (s *adminServer) PauseEvents(ctx context.Context, req *protoc.PauseEventsRequest) (*protoc.PauseEventsResponse, error) {
+	if err := s.admit(ctx, "PauseEvents", req); err != nil {
+		return nil, err
+	}
+	if req.Pattern == "" {
+		return nil, status.Error(codes.InvalidArgument, "pattern is required")
+	}
+	s.engine.Pause(req.Pattern)
+	return &protoc.PauseEventsResponse{}, nil
+}
+
+func (s *adminServer) ResumeEvents(ctx context.Context, req *protoc.ResumeEventsRequest) (*protoc.ResumeEventsResponse, error) {
+	if err := s.admit(ctx, "ResumeEvents", req); err != nil {
+		return nil, err
+	}
+	if req.Pattern == "" {
+		return nil, status.Error(codes.InvalidArgument, "pattern is required")
+	}
+	return &protoc.ResumeEventsResponse{Released: int32(s.engine.Resume(req.Pattern))}, nil
+}
+
+func (s *adminServer) ListDeadLetters(ctx context.Context, req *protoc.ListDeadLettersRequest) (*protoc.ListDeadLettersResponse, error) {
+	if err := s.admit(ctx, "ListDeadLetters", req); err != nil {
+		return nil, err
+	}
+	resp := &protoc.ListDeadLettersResponse{}
+	for _, letter := range s.engine.DeadLetters() {
+		data, err := sonicApi.Marshal(letter.Event.Data)
+		if err != nil {
+			data = nil // Keep listing letters whose data cannot be encoded
+		}
+		resp.DeadLetters = append(resp.DeadLetters, &protoc.DeadLetter{
+			Id:        letter.ID,
+			EventId:   letter.Event.ID,
+			EventName: letter.Event.Name,
+			Timestamp: timestamppb.New(letter.Event.Timestamp),
+			Data:      string(data),
+			Error:     letter.Err.Error(),
+			FailedAt:  timestamppb.New(letter.FailedAt),
+		})
+	}
+	return resp, nil
+}
+
+func (s *adminServer) ReplayDeadLetters(ctx context.Context, req *protoc.ReplayDeadLettersRequest) (*protoc.ReplayDeadLettersResponse, error) {
+	if err := s.admit(ctx, "ReplayDeadLetters", req); err != nil {
+		return nil, err
+	}
+	replayed, err := s.engine.Replay(ctx, req.Ids...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &protoc.ReplayDeadLettersResponse{Replayed: int32(replayed)}, nil
+}
+
+// AdminClient calls the admin service of a remote engine.
+type AdminClient struct {
+	client protoc.AdminServiceClient
+	config *transportConfig
+	clock  Clock
+}
+
+// NewAdminClient creates an AdminClient for the admin service behind conn. The options
+// configure the credentials sent with every call.
+func NewAdminClient(conn grpc.ClientConnInterface, opts ...TransportOption) *AdminClient {
+	return &AdminClient{client: protoc.NewAdminServiceClient(conn), config: newTransportConfig(opts), clock: RealClock{}}
+}
+
+// outgoing adds the credentials for a call of an admin RPC with the request to ctx, along
+// with the time of the call, see RegisterAdminService.
+func (c *AdminClient) outgoing(ctx context.Context, rpc string, req proto.Message) (context.Context, error) {
+	data, err := marshalAdminRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.clock.Now()
+	header := Header{timestampHeader: now.UTC().Format(time.RFC3339Nano)}
+	c.config.authorize(header, Event{Name: "admin." + rpc, Timestamp: now}, data)
+	return outgoingContext(ctx, header), nil
+}
+
+// ListEvents returns the event names and patterns with subscribed handlers on the remote engine.
+func (c *AdminClient) ListEvents(ctx context.Context) ([]EventInfo, error) {
+	req := &protoc.ListEventsRequest{}
+	callCtx, err := c.outgoing(ctx, "ListEvents", req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.ListEvents(callCtx, req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	events := make([]EventInfo, 0, len(resp.Events))
+	for _, info := range resp.Events {
+		events = append(events, EventInfo{
+			Name:     info.Name,
+			Pattern:  info.Pattern,
+			Handlers: int(info.Handlers),
+		})
+	}
+	return events, nil
+}
+
+// Stats returns the dispatch statistics of the remote engine, limited to the given event names if any.
+func (c *AdminClient) Stats(ctx context.Context, eventNames ...string) ([]EventStats, error) {
+	req := &protoc.GetStatsRequest{EventNames: eventNames}
+	callCtx, err := c.outgoing(ctx, "GetStats", req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.GetStats(callCtx, req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	stats := make([]EventStats, 0, len(resp.Stats))
+	for _, st := range resp.Stats {
+		stats = append(stats, EventStats{
+			Name:         st.Name,
+			Dispatched:   st.Dispatched,
+			Failed:       st.Failed,
+			Canceled:     st.Canceled,
+			TotalLatency: st.TotalLatency.AsDuration(),
+			MaxLatency:   st.MaxLatency.AsDuration(),
+		})
+	}
+	return stats, nil
+}
+
+// Pause holds back events matching the pattern on the remote engine, see Engine.Pause.
+func (c *AdminClient) Pause(ctx context.Context, pattern string) error {
+	req := &protoc.PauseEventsRequest{Pattern: pattern}
+	callCtx, err := c.outgoing(ctx, "PauseEvents", req)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.PauseEvents(callCtx, req)
+	return fromStatus(err)
+}
+
+// Resume dispatches the events held back for the pattern on the remote engine and
+// returns their number, see Engine.Resume.
+func (c *AdminClient) Resume(ctx context.Context, pattern string) (int, error) {
+	req := &protoc.ResumeEventsRequest{Pattern: pattern}
+	callCtx, err := c.outgoing(ctx, "ResumeEvents", req)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.client.ResumeEvents(callCtx, req)
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return int(resp.Released), nil
+}
+
+// DeadLetters returns the dead letters of the remote engine. Their event data is
+// decoded into generic JSON values and their errors only keep the message.
+func (c *AdminClient) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	req := &protoc.ListDeadLettersRequest{}
+	callCtx, err := c.outgoing(ctx, "ListDeadLetters", req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.ListDeadLetters(callCtx, req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+
+	letters := make([]DeadLetter, 0, len(resp.DeadLetters))
+	for _, letter := range resp.DeadLetters {
+		var data any
+		if letter.Data != "" {
+			if err := sonicApi.UnmarshalFromString(letter.Data, &data); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+			}
+		}
+		letters = append(letters, DeadLetter{
+			ID: letter.Id,
+			Event: Event{
+				ID:        letter.EventId,
+				Name:      letter.EventName,
+				Context:   ctx,
+				Timestamp: letter.Timestamp.AsTime(),
+				Data:      data,
+			},
+			Err:      errors.New(letter.Error),
+			FailedAt: letter.FailedAt.AsTime(),
+		})
+	}
+	return letters, nil
+}
+
+// Replay dispatches dead letters of the remote engine again and returns how many were
+// handled successfully. The IDs are those of the dead letters, see DeadLetter.ID. Without
+// IDs all dead letters are replayed.
+func (c *AdminClient) Replay(ctx context.Context, ids ...string) (int, error) {
+	req := &protoc.ReplayDeadLettersRequest{Ids: ids}
+	callCtx, err := c.outgoing(ctx, "ReplayDeadLetters", req)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.client.ReplayDeadLetters(callCtx, req)
+	if err != nil {
+		return 0, fromStatus(err)
+	}
+	return int(resp.Replayed), nil
+}
@@ -0,0 +1,291 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+	protoc "github.com/YONEDASH/beacon/internal/protoc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestPauseResume(t *testing.T) {
+	engine := beacon.New()
+
+	var runs atomic.Int32
+	engine.Subscribe("order.placed", func(e beacon.Event) error {
+		runs.Add(1)
+		return nil
+	})
+
+	engine.Pause("order.*")
+	result, err := engine.SubmitWithResult(context.Background(), "order.placed", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Local.Paused || runs.Load() != 0 {
+		t.Fatalf("expected event to be held, got %+v with %d runs", result.Local, runs.Load())
+	}
+
+	if released := engine.Resume("order.*"); released != 1 {
+		t.Errorf("expected 1 released event, got %d", released)
+	}
+	waitFor(t, func() bool { return runs.Load() == 1 })
+}
+
+func TestPauseHoldLimit(t *testing.T) {
+	engine := beacon.New(beacon.WithHoldLimit(2))
+	engine.Subscribe("order.placed", func(e beacon.Event) error { return nil })
+
+	engine.Pause("order.*")
+	for range 2 {
+		if err := engine.Submit("order.placed", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := engine.Submit("order.placed", nil); !errors.Is(err, beacon.ErrHoldLimit) {
+		t.Errorf("expected ErrHoldLimit, got %v", err)
+	}
+	if released := engine.Resume("order.*"); released != 2 {
+		t.Errorf("expected 2 released events, got %d", released)
+	}
+}
+
+func TestResumeErrors(t *testing.T) {
+	failed := make(chan error, 1)
+	engine := beacon.New(
+		beacon.WithDeadLetters(beacon.NewMemoryDeadLetterStore(10)),
+		beacon.WithErrorHandler(func(e beacon.Event, err error) { failed <- err }),
+	)
+	engine.Subscribe("order.placed", func(e beacon.Event) error {
+		return errors.New("warehouse unavailable")
+	})
+
+	engine.Pause("order.*")
+	engine.Submit("order.placed", nil)
+	engine.Resume("order.*")
+
+	if err := <-failed; err == nil || !strings.Contains(err.Error(), "warehouse unavailable") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if letters := engine.DeadLetters(); len(letters) != 1 {
+		t.Errorf("expected a dead letter, got %+v", letters)
+	}
+}
+
+func TestDeadLetters(t *testing.T) {
+	engine := beacon.New(beacon.WithDeadLetters(beacon.NewMemoryDeadLetterStore(10)))
+
+	fail := true
+	engine.Subscribe("invoice.sent", func(e beacon.Event) error {
+		if fail {
+			return errors.New("mail server unavailable")
+		}
+		return nil
+	})
+
+	if err := engine.Submit("invoice.sent", "INV-1"); err == nil {
+		t.Fatal("expected handler error")
+	}
+	letters := engine.DeadLetters()
+	if len(letters) != 1 || letters[0].Event.Data != "INV-1" {
+		t.Fatalf("unexpected dead letters: %+v", letters)
+	}
+
+	fail = false
+	replayed, err := engine.Replay(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != 1 || len(engine.DeadLetters()) != 0 {
+		t.Errorf("expected dead letter to be replayed, got %d replayed and %d left", replayed, len(engine.DeadLetters()))
+	}
+
+	stats := engine.Stats()
+	if len(stats) != 1 || stats[0].Dispatched != 2 || stats[0].Failed != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestStatsWithoutHandlers(t *testing.T) {
+	engine := beacon.New()
+	engine.Subscribe("order.placed", func(e beacon.Event) error { return nil })
+
+	for _, name := range []string{"order.placed", "bogus.1", "bogus.2"} {
+		if err := engine.Submit(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := engine.Stats(); len(stats) != 1 || stats[0].Name != "order.placed" {
+		t.Errorf("expected stats only for events with handlers, got %+v", stats)
+	}
+}
+
+func TestDeadLettersWithoutEventIDs(t *testing.T) {
+	engine := beacon.New(beacon.WithDeadLetters(beacon.NewMemoryDeadLetterStore(10)))
+
+	failing := map[string]bool{"INV-1": true, "INV-2": true}
+	engine.Subscribe("invoice.sent", func(e beacon.Event) error {
+		if failing[e.Data.(string)] {
+			return errors.New("mail server unavailable")
+		}
+		return nil
+	})
+
+	// Webhooks arrive without event IDs
+	handler := beacon.NewHTTPHandler(engine)
+	for _, invoice := range []string{"INV-1", "INV-2"} {
+		body := `{"event_name": "invoice.sent", "data": "` + invoice + `"}`
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	}
+	letters := engine.DeadLetters()
+	if len(letters) != 2 || letters[0].ID == letters[1].ID {
+		t.Fatalf("expected two dead letters with distinct IDs, got %+v", letters)
+	}
+
+	failing["INV-2"] = false
+	replayed, err := engine.Replay(context.Background(), letters[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left := engine.DeadLetters(); replayed != 1 || len(left) != 1 || left[0].Event.Data != "INV-1" {
+		t.Errorf("expected only INV-2 to be replayed, got %d replayed and %+v left", replayed, left)
+	}
+}
+
+func TestAdminClient(t *testing.T) {
+	receiver := beacon.New(beacon.WithDeadLetters(beacon.NewMemoryDeadLetterStore(10)))
+	receiver.Subscribe("invoice.sent", func(e beacon.Event) error {
+		return errors.New("mail server unavailable")
+	})
+	conn := startServer(t, receiver, beacon.WithAdminService(), beacon.WithServiceOptions(
+		beacon.WithAuthenticator(beacon.BearerTokenAuthenticator(map[string]string{"secret": "operator"})),
+	))
+	admin := beacon.NewAdminClient(conn, beacon.WithBearerToken("secret"))
+	ctx := context.Background()
+
+	receiver.Submit("invoice.sent", "INV-1")
+
+	stats, err := admin.Stats(ctx, "invoice.sent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Failed != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	letters, err := admin.DeadLetters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].Event.Data != "INV-1" || letters[0].Err.Error() != "mail server unavailable" {
+		t.Errorf("unexpected dead letters: %+v", letters)
+	}
+
+	if err := admin.Pause(ctx, "invoice.*"); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := admin.Replay(ctx, letters[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed != 1 {
+		t.Errorf("expected held event to count as replayed, got %d", replayed)
+	}
+	released, err := admin.Resume(ctx, "invoice.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 1 {
+		t.Errorf("expected 1 released event, got %d", released)
+	}
+	waitFor(t, func() bool { return len(receiver.DeadLetters()) == 1 })
+}
+
+func TestAdminClientAuthentication(t *testing.T) {
+	receiver := beacon.New()
+	ctx := context.Background()
+
+	unprotected := startServer(t, receiver, beacon.WithAdminService())
+	if _, err := beacon.NewAdminClient(unprotected).ListEvents(ctx); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated without an authenticator, got %v", err)
+	}
+
+	conn := startServer(t, receiver, beacon.WithAdminService(), beacon.WithServiceOptions(
+		beacon.WithAuthenticator(beacon.BearerTokenAuthenticator(map[string]string{"secret": "operator", "viewer": "dashboard"})),
+		beacon.WithAuthorizer(beacon.RuleAuthorizer(map[string][]string{
+			"operator":  {"admin.*"},
+			"dashboard": {"admin.GetStats", "admin.ListEvents"},
+		})),
+	))
+	if err := beacon.NewAdminClient(conn).Pause(ctx, "order.*"); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated without a token, got %v", err)
+	}
+	if err := beacon.NewAdminClient(conn, beacon.WithBearerToken("viewer")).Pause(ctx, "order.*"); !errors.Is(err, beacon.ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+	if _, err := beacon.NewAdminClient(conn, beacon.WithBearerToken("viewer")).Stats(ctx); err != nil {
+		t.Errorf("expected stats to be readable, got %v", err)
+	}
+	if err := beacon.NewAdminClient(conn, beacon.WithBearerToken("secret")).Pause(ctx, "order.*"); err != nil {
+		t.Errorf("expected operator to pause events, got %v", err)
+	}
+}
+
+func TestAdminClientHMAC(t *testing.T) {
+	clock := beacontest.NewClock(time.Now())
+	receiver := beacon.New(beacon.WithClock(clock))
+	keys := map[string][]byte{"operator": []byte("k3y")}
+	conn := startServer(t, receiver, beacon.WithAdminService(), beacon.WithServiceOptions(
+		beacon.WithAuthenticator(beacon.HMACAuthenticator(keys, time.Minute)),
+		beacon.WithAuthorizer(beacon.RuleAuthorizer(map[string][]string{"operator": {"admin.*"}})),
+	))
+	ctx := context.Background()
+
+	admin := beacon.NewAdminClient(conn, beacon.WithHMACKey("operator", keys["operator"]))
+	if err := admin.Pause(ctx, "order.*"); err != nil {
+		t.Fatalf("expected signed call to be accepted, got %v", err)
+	}
+
+	// A signature does not cover a different request
+	tampered, err := grpc.NewClient(conn.Target(), grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if pause, ok := req.(*protoc.PauseEventsRequest); ok {
+				pause.Pattern = "*"
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tampered.Close()
+	if err := beacon.NewAdminClient(tampered, beacon.WithHMACKey("operator", keys["operator"])).Pause(ctx, "order.*"); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated for a tampered request, got %v", err)
+	}
+
+	// Signatures expire with the allowed skew
+	clock.Advance(time.Hour)
+	if err := admin.Pause(ctx, "order.*"); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated outside of the skew, got %v", err)
+	}
+}
+
+// waitFor polls a condition until it holds or a second has passed.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,162 @@
+package beacon
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime"
+)
+
+// partitionKey extracts the partition key of events whose names match a pattern.
+type partitionKey struct {
+	pattern string
+	key     func(Event) string
+}
+
+// WithWorkers configures the number of workers dispatching events submitted with
+// SubmitAsync and how many events each of them queues. By default there is one worker
+// per CPU, each queueing 64 events.
+func WithWorkers(workers, queueSize int) Option {
+	return func(engine *Engine) {
+		engine.workers = workers
+		engine.queueSize = queueSize
+	}
+}
+
+// WithPartitionKey routes asynchronous events whose names match the pattern to a worker
+// by the key extracted from them. Events with the same key are handled one after another
+// in the order they were submitted, while events with different keys run in parallel.
+// Events without a partition key are spread over the workers by their ID.
+func WithPartitionKey(pattern string, key func(e Event) string) Option {
+	return func(engine *Engine) {
+		engine.partitionKeys = append(engine.partitionKeys, partitionKey{pattern: pattern, key: key})
+	}
+}
+
+// WithErrorHandler configures a function receiving the errors of events submitted with
+// SubmitAsync and of events delivered late by Debounce or Throttle, which have no caller
+// to return them to.
+func WithErrorHandler(handler func(e Event, err error)) Option {
+	return func(engine *Engine) {
+		engine.errorHandler = handler
+	}
+}
+
+// SubmitAsync queues an event to be dispatched by the workers of the engine and returns
+// without waiting for its handlers. It only blocks while the queue of the worker is full
+// or a rate limit with BlockWhenLimited applies, at most until ctx is done. The event
+// context keeps the values of ctx but is not canceled with it. Errors are passed to the handler configured with WithErrorHandler.
+// After Close it fails with ErrClosed.
+func (s *Engine) SubmitAsync(ctx context.Context, eventName string, data any) error {
+	if eventName == "" {
+		return ErrEventNameRequired
+	}
+	if dropped, err := applyRateLimits(ctx, s.rateLimits, eventName); dropped || err != nil {
+		return err
+	}
+
+	s.asyncMu.RLock()
+	defer s.asyncMu.RUnlock()
+
+	for s.queues == nil {
+		if s.asyncStopped {
+			return ErrClosed
+		}
+		// Start the workers on first use, upgrading to a write lock. Close may stop them
+		// before the read lock is taken again, so the queues are checked once more.
+		s.asyncMu.RUnlock()
+		s.startWorkers()
+		s.asyncMu.RLock()
+	}
+
+	if err := s.beginDispatch(); err != nil {
+		return err
+	}
+
+	event := s.newEvent(context.WithoutCancel(ctx), eventName, data)
+	queue := s.queues[s.partition(event)]
+	select {
+	case queue <- event:
+		return nil
+	case <-ctx.Done():
+		s.endDispatch()
+		return ctx.Err()
+	}
+}
+
+// startWorkers starts the workers dispatching asynchronous events unless they are running
+// or were stopped.
+func (s *Engine) startWorkers() {
+	s.asyncMu.Lock()
+	defer s.asyncMu.Unlock()
+
+	if s.queues != nil || s.asyncStopped {
+		return
+	}
+
+	workers, queueSize := s.workers, s.queueSize
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if queueSize <= 0 {
+		queueSize = 64
+	}
+
+	s.queues = make([]chan Event, workers)
+	for i := range s.queues {
+		queue := make(chan Event, queueSize)
+		s.queues[i] = queue
+
+		s.asyncDone.Add(1)
+		go func() {
+			defer s.asyncDone.Done()
+			for event := range queue {
+				s.dispatchAsync(event)
+			}
+		}()
+	}
+}
+
+// stopWorkers stops the workers once they dispatched the queued events.
+func (s *Engine) stopWorkers() {
+	s.asyncMu.Lock()
+	for _, queue := range s.queues {
+		close(queue)
+	}
+	s.queues = nil
+	s.asyncStopped = true
+	s.asyncMu.Unlock()
+
+	s.asyncDone.Wait()
+}
+
+// dispatchAsync delivers an event queued by SubmitAsync to the remotes and local handlers.
+func (s *Engine) dispatchAsync(event Event) {
+	defer s.endDispatch()
+
+	var err error
+	if len(s.remotes) > 0 {
+		var submitResult SubmitResult
+		err = s.postRemotes(event.Context, event, &submitResult)
+	}
+	if err == nil {
+		_, err = s.dispatchEvent(event.Name, event)
+	}
+	if err != nil && s.errorHandler != nil {
+		s.errorHandler(event, err)
+	}
+}
+
+// partition returns the index of the worker dispatching an event.
+func (s *Engine) partition(event Event) int {
+	key := event.ID
+	for _, pk := range s.partitionKeys {
+		if matchEventName(pk.pattern, event.Name) {
+			key = pk.key(event)
+			break
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(s.queues)))
+}
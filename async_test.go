@@ -0,0 +1,134 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+)
+
+type OrderUpdated struct {
+	OrderID string
+	Seq     int
+}
+
+func TestSubmitAsyncPartitionOrder(t *testing.T) {
+	engine := beacon.New(
+		beacon.WithWorkers(4, 16),
+		beacon.WithPartitionKey("order.*", func(e beacon.Event) string {
+			return e.Data.(OrderUpdated).OrderID
+		}),
+	)
+
+	var mu sync.Mutex
+	seen := map[string][]int{}
+	engine.Subscribe("order.updated", func(e beacon.Event) error {
+		update := e.Data.(OrderUpdated)
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		seen[update.OrderID] = append(seen[update.OrderID], update.Seq)
+		return nil
+	})
+
+	for seq := range 10 {
+		for order := range 5 {
+			update := OrderUpdated{OrderID: fmt.Sprintf("order-%d", order), Seq: seq}
+			if err := engine.SubmitAsync(context.Background(), "order.updated", update); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Close waits for queued events
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for order, seqs := range seen {
+		if len(seqs) != 10 {
+			t.Errorf("%s: expected 10 events, got %d", order, len(seqs))
+		}
+		for i, seq := range seqs {
+			if seq != i {
+				t.Errorf("%s: events out of order: %v", order, seqs)
+				break
+			}
+		}
+	}
+}
+
+func TestSubmitAsyncErrorHandler(t *testing.T) {
+	failed := make(chan error, 1)
+	engine := beacon.New(beacon.WithErrorHandler(func(e beacon.Event, err error) {
+		failed <- err
+	}))
+	engine.Subscribe("test", func(e beacon.Event) error {
+		return errors.New("some error message")
+	})
+
+	if err := engine.SubmitAsync(context.Background(), "test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-failed; err == nil || err.Error() != "some error message" {
+		t.Errorf("expected handler error, got %v", err)
+	}
+}
+
+func TestSubmitAsyncDrain(t *testing.T) {
+	engine := beacon.New(beacon.WithWorkers(1, 4))
+
+	finished := 0
+	engine.Subscribe("slow", func(e beacon.Event) error {
+		time.Sleep(10 * time.Millisecond)
+		finished++
+		return nil
+	})
+
+	for range 3 {
+		if err := engine.SubmitAsync(context.Background(), "slow", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := engine.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if finished != 3 {
+		t.Errorf("expected queued events to finish before drain returned, got %d", finished)
+	}
+	if err := engine.SubmitAsync(context.Background(), "slow", nil); !errors.Is(err, beacon.ErrDraining) {
+		t.Errorf("expected ErrDraining, got %v", err)
+	}
+}
+
+func TestSubmitAsyncClose(t *testing.T) {
+	engine := beacon.New(beacon.WithWorkers(2, 4))
+	engine.Subscribe("test", func(e beacon.Event) error { return nil })
+
+	// Submissions racing with Close either get queued or fail with ErrClosed
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if err := engine.SubmitAsync(context.Background(), "test", nil); err != nil && !errors.Is(err, beacon.ErrClosed) {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	engine.Close()
+	wg.Wait()
+
+	if err := engine.SubmitAsync(context.Background(), "test", nil); !errors.Is(err, beacon.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
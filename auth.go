@@ -0,0 +1,263 @@
+package beacon
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Headers used to authenticate events.
+const (
+	authorizationHeader = "authorization"
+	keyIDHeader         = "beacon-key-id"
+	signatureHeader     = "beacon-signature"
+	timestampHeader     = "beacon-timestamp"
+)
+
+// IncomingEvent is an event received by the event service before it is decoded.
+type IncomingEvent struct {
+	ID        string
+	Name      string
+	Timestamp time.Time
+	// Data is the encoded event data as sent by the client.
+	Data []byte
+	// Header holds the gRPC metadata or HTTP headers of the request.
+	Header Carrier
+	// TLS is the state of the connection, or nil if it is not encrypted.
+	TLS *tls.ConnectionState
+}
+
+// Principal is the authenticated identity of a client.
+type Principal struct {
+	Name       string
+	Attributes map[string]string
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal that submitted the event handled with ctx.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// Authenticator identifies the client that submitted an event.
+type Authenticator interface {
+	Authenticate(ctx context.Context, in *IncomingEvent) (*Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(ctx context.Context, in *IncomingEvent) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, in *IncomingEvent) (*Principal, error) {
+	return f(ctx, in)
+}
+
+// Authorizer decides whether a principal may submit an event.
+type Authorizer interface {
+	Authorize(ctx context.Context, principal *Principal, eventName string) error
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface.
+type AuthorizerFunc func(ctx context.Context, principal *Principal, eventName string) error
+
+func (f AuthorizerFunc) Authorize(ctx context.Context, principal *Principal, eventName string) error {
+	return f(ctx, principal, eventName)
+}
+
+// WithAuthenticator rejects events from clients the authenticator cannot identify with
+// ErrUnauthenticated. The principal is available to handlers through PrincipalFromContext.
+func WithAuthenticator(authenticator Authenticator) ServiceOption {
+	return func(s *server) {
+		s.authenticator = authenticator
+	}
+}
+
+// WithAuthorizer rejects events the authenticated principal may not submit with
+// ErrPermissionDenied. It has no effect without WithAuthenticator.
+func WithAuthorizer(authorizer Authorizer) ServiceOption {
+	return func(s *server) {
+		s.authorizer = authorizer
+	}
+}
+
+// Authenticators tries each authenticator in turn and returns the first principal identified.
+func Authenticators(authenticators ...Authenticator) Authenticator {
+	return authenticatorChain(authenticators)
+}
+
+// authenticatorChain is the Authenticator returned by Authenticators.
+type authenticatorChain []Authenticator
+
+func (c authenticatorChain) Authenticate(ctx context.Context, in *IncomingEvent) (*Principal, error) {
+	errs := make([]error, 0, len(c))
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, in)
+		if err == nil {
+			return principal, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// useClock hands the clock of the engine to the authenticators using one.
+func (c authenticatorChain) useClock(clock Clock) {
+	for _, authenticator := range c {
+		if user, ok := authenticator.(clockUser); ok {
+			user.useClock(clock)
+		}
+	}
+}
+
+// BearerTokenAuthenticator authenticates clients sending one of the tokens in the
+// "authorization: Bearer <token>" header. The map assigns principal names to tokens.
+func BearerTokenAuthenticator(tokens map[string]string) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, in *IncomingEvent) (*Principal, error) {
+		token, ok := strings.CutPrefix(in.Header.Get(authorizationHeader), "Bearer ")
+		if !ok {
+			return nil, errors.New("missing bearer token")
+		}
+		for candidate, name := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+				return &Principal{Name: name}, nil
+			}
+		}
+		return nil, errors.New("invalid bearer token")
+	})
+}
+
+// MTLSAuthenticator authenticates clients by the common name of their verified TLS
+// certificate. The server must be configured to require and verify client certificates.
+func MTLSAuthenticator() Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, in *IncomingEvent) (*Principal, error) {
+		if in.TLS == nil || len(in.TLS.VerifiedChains) == 0 || len(in.TLS.VerifiedChains[0]) == 0 {
+			return nil, errors.New("missing verified client certificate")
+		}
+
+		cert := in.TLS.VerifiedChains[0][0]
+		principal := &Principal{Name: cert.Subject.CommonName, Attributes: map[string]string{}}
+		if len(cert.DNSNames) > 0 {
+			principal.Attributes["dns"] = strings.Join(cert.DNSNames, ",")
+		}
+		if len(cert.Subject.Organization) > 0 {
+			principal.Attributes["organization"] = strings.Join(cert.Subject.Organization, ",")
+		}
+		return principal, nil
+	})
+}
+
+// HMACAuthenticator authenticates clients signing events with one of the keys, as done by
+// transports configured with WithHMACKey. The key ID becomes the principal name. Events
+// whose timestamp differs from the current time of the engine's clock by more than maxSkew
+// are rejected, unless maxSkew is zero.
+func HMACAuthenticator(keys map[string][]byte, maxSkew time.Duration) Authenticator {
+	return &hmacAuthenticator{keys: keys, maxSkew: maxSkew, clock: RealClock{}}
+}
+
+// hmacAuthenticator is the Authenticator returned by HMACAuthenticator.
+type hmacAuthenticator struct {
+	keys    map[string][]byte
+	maxSkew time.Duration
+	clock   Clock
+}
+
+func (a *hmacAuthenticator) Authenticate(ctx context.Context, in *IncomingEvent) (*Principal, error) {
+	keyID := in.Header.Get(keyIDHeader)
+	key, ok := a.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+
+	signature, err := hex.DecodeString(in.Header.Get(signatureHeader))
+	if err != nil || !hmac.Equal(signature, signEvent(key, in.ID, in.Name, in.Timestamp, in.Data)) {
+		return nil, errors.New("invalid signature")
+	}
+
+	if a.maxSkew > 0 {
+		if skew := a.clock.Now().Sub(in.Timestamp); skew > a.maxSkew || skew < -a.maxSkew {
+			return nil, errors.New("event timestamp outside of allowed skew")
+		}
+	}
+	return &Principal{Name: keyID}, nil
+}
+
+// useClock makes the authenticator check timestamps by the clock of the engine using it.
+func (a *hmacAuthenticator) useClock(clock Clock) {
+	a.clock = clock
+}
+
+// signEvent computes the HMAC-SHA256 signature of an event.
+func signEvent(key []byte, id, name string, timestamp time.Time, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n", id, name, timestamp.UTC().Format(time.RFC3339Nano))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// RuleAuthorizer allows principals to submit events whose names match one of their
+// patterns. The map assigns patterns to principal names; patterns assigned to "*"
+// apply to all principals.
+func RuleAuthorizer(rules map[string][]string) Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, principal *Principal, eventName string) error {
+		for _, name := range []string{principal.Name, "*"} {
+			for _, pattern := range rules[name] {
+				if matchEventName(pattern, eventName) {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("%s may not submit %s", principal.Name, eventName)
+	})
+}
+
+// TransportOption is a functional option for configuring the gRPC and HTTP transports.
+type TransportOption func(*transportConfig)
+
+// transportConfig holds the credentials a transport sends with events.
+type transportConfig struct {
+	bearerToken string
+	hmacKeyID   string
+	hmacKey     []byte
+}
+
+// WithBearerToken sends a bearer token with every event, see BearerTokenAuthenticator.
+func WithBearerToken(token string) TransportOption {
+	return func(c *transportConfig) {
+		c.bearerToken = token
+	}
+}
+
+// WithHMACKey signs every event with a key, see HMACAuthenticator.
+func WithHMACKey(keyID string, key []byte) TransportOption {
+	return func(c *transportConfig) {
+		c.hmacKeyID = keyID
+		c.hmacKey = key
+	}
+}
+
+func newTransportConfig(opts []TransportOption) *transportConfig {
+	config := &transportConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// authorize adds the credentials for an event with the encoded data to the carrier.
+func (c *transportConfig) authorize(carrier Carrier, e Event, data []byte) {
+	if c.bearerToken != "" {
+		carrier.Set(authorizationHeader, "Bearer "+c.bearerToken)
+	}
+	if c.hmacKey != nil {
+		carrier.Set(keyIDHeader, c.hmacKeyID)
+		carrier.Set(signatureHeader, hex.EncodeToString(signEvent(c.hmacKey, e.ID, e.Name, e.Timestamp, data)))
+	}
+}
@@ -0,0 +1,179 @@
+package beacon_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestBearerTokenAuthentication(t *testing.T) {
+	receiver := beacon.New()
+	conn := startRemote(t, receiver,
+		beacon.WithAuthenticator(beacon.BearerTokenAuthenticator(map[string]string{"secret": "billing"})),
+	)
+
+	var principal *beacon.Principal
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		principal, _ = beacon.PrincipalFromContext(e.Context)
+		return nil
+	})
+
+	sender := beacon.New(beacon.WithTransport(beacon.NewGRPCTransport(conn, beacon.WithBearerToken("secret"))))
+	if err := sender.Submit("test", nil); err != nil {
+		t.Fatal(err)
+	}
+	if principal == nil || principal.Name != "billing" {
+		t.Errorf("unexpected principal: %+v", principal)
+	}
+
+	anonymous := beacon.New(beacon.WithTransport(beacon.NewGRPCTransport(conn)))
+	if err := anonymous.Submit("test", nil); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+
+	if _, err := anonymous.SubscribeRemote("test", func(e beacon.Event) error { return nil }); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated for subscription, got %v", err)
+	}
+
+	wrong := beacon.New(beacon.WithTransport(beacon.NewGRPCTransport(conn, beacon.WithBearerToken("guess"))))
+	if err := wrong.Submit("test", nil); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestHMACAuthentication(t *testing.T) {
+	receiver := beacon.New()
+	server := httptest.NewServer(beacon.NewHTTPHandler(receiver,
+		beacon.WithAuthenticator(beacon.HMACAuthenticator(map[string][]byte{"orders": []byte("key")}, time.Minute)),
+	))
+	defer server.Close()
+
+	var message any
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		message = e.Data
+		return nil
+	})
+
+	sender := beacon.New(beacon.WithTransport(beacon.NewHTTPTransport(server.URL, nil, beacon.WithHMACKey("orders", []byte("key")))))
+	if err := sender.Submit("test", map[string]any{"id": 42}); err != nil {
+		t.Fatal(err)
+	}
+	if message == nil {
+		t.Error("handler did not run")
+	}
+
+	forger := beacon.New(beacon.WithTransport(beacon.NewHTTPTransport(server.URL, nil, beacon.WithHMACKey("orders", []byte("guess")))))
+	if err := forger.Submit("test", nil); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestHMACAuthenticationClock(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	receiver := beacon.New(beacon.WithClock(clock))
+	server := httptest.NewServer(beacon.NewHTTPHandler(receiver,
+		beacon.WithAuthenticator(beacon.Authenticators(
+			beacon.HMACAuthenticator(map[string][]byte{"orders": []byte("key")}, time.Minute),
+		)),
+	))
+	defer server.Close()
+	receiver.Subscribe("test", func(e beacon.Event) error { return nil })
+
+	// The skew is measured by the clock of the receiving engine
+	sender := beacon.New(
+		beacon.WithClock(clock),
+		beacon.WithTransport(beacon.NewHTTPTransport(server.URL, nil, beacon.WithHMACKey("orders", []byte("key")))),
+	)
+	if err := sender.Submit("test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	late := beacon.New(beacon.WithTransport(beacon.NewHTTPTransport(server.URL, nil, beacon.WithHMACKey("orders", []byte("key")))))
+	if err := late.Submit("test", nil); !errors.Is(err, beacon.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated for a skewed timestamp, got %v", err)
+	}
+}
+
+func TestSubscribeRemoteClock(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	receiver := beacon.New(beacon.WithClock(clock))
+	conn := startRemote(t, receiver,
+		beacon.WithAuthenticator(beacon.HMACAuthenticator(map[string][]byte{"orders": []byte("key")}, time.Minute)),
+	)
+
+	// Subscription requests are signed with the time of the subscribing engine
+	subscriber := beacon.New(
+		beacon.WithClock(clock),
+		beacon.WithTransport(beacon.NewGRPCTransport(conn, beacon.WithHMACKey("orders", []byte("key")))),
+	)
+	unsubscribe, err := subscriber.SubscribeRemote("order.*", func(e beacon.Event) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsubscribe()
+}
+
+func TestRuleAuthorizer(t *testing.T) {
+	receiver := beacon.New()
+	conn := startRemote(t, receiver,
+		beacon.WithAuthenticator(beacon.BearerTokenAuthenticator(map[string]string{"secret": "billing"})),
+		beacon.WithAuthorizer(beacon.RuleAuthorizer(map[string][]string{
+			"billing": {"invoice.*"},
+			"*":       {"ping"},
+		})),
+	)
+
+	runs := 0
+	handler := func(e beacon.Event) error {
+		runs++
+		return nil
+	}
+	receiver.Subscribe("invoice.paid", handler)
+	receiver.Subscribe("ping", handler)
+	receiver.Subscribe("user.deleted", handler)
+
+	sender := beacon.New(beacon.WithTransport(beacon.NewGRPCTransport(conn, beacon.WithBearerToken("secret"))))
+	for _, name := range []string{"invoice.paid", "ping"} {
+		if err := sender.Submit(name, nil); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if err := sender.Submit("user.deleted", nil); !errors.Is(err, beacon.ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+	if runs != 2 {
+		t.Errorf("expected 2 handler runs, got %d", runs)
+	}
+}
+
+func TestSubscribeAuthorization(t *testing.T) {
+	receiver := beacon.New()
+	conn := startRemote(t, receiver,
+		beacon.WithAuthenticator(beacon.BearerTokenAuthenticator(map[string]string{"secret": "billing"})),
+		beacon.WithAuthorizer(beacon.RuleAuthorizer(map[string][]string{"billing": {"order.?"}})),
+		beacon.WithDeniedEvents("order.9"),
+	)
+
+	received := make(chan string, 8)
+	subscriber := beacon.New(beacon.WithTransport(beacon.NewGRPCTransport(conn, beacon.WithBearerToken("secret"))))
+	// The pattern itself passes the rule, the events it matches do not all pass it
+	unsubscribe, err := subscriber.SubscribeRemote("order.*", func(e beacon.Event) error {
+		received <- e.Name
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	for _, name := range []string{"order.created", "order.9", "order.1"} {
+		receiver.Submit(name, nil)
+	}
+	if name := <-received; name != "order.1" {
+		t.Errorf("expected only order.1 to be streamed, got %s", name)
+	}
+}
@@ -0,0 +1,189 @@
+package beacon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchHandler handles a batch of events, oldest first.
+type BatchHandler func(events []Event) error
+
+// BatchSettings configures when the events of a batch subscription are delivered.
+type BatchSettings struct {
+	// MaxSize is the number of events delivering a batch. Defaults to 100.
+	MaxSize int
+	// MaxWait is how long the first event of a batch waits for more events before the batch
+	// is delivered anyway. Defaults to one second.
+	MaxWait time.Duration
+	// MaxRetries is how often a failed batch is retried. Zero delivers it only once.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled for every further one.
+	// Defaults to 100 milliseconds.
+	RetryBackoff time.Duration
+}
+
+// SubscribeBatch adds a handler receiving the events with the given name in batches.
+// Events are collected until MaxSize of them arrived or the first of them waited for
+// MaxWait. Submitting an event returns once it is added to the batch.
+//
+// A failed batch is retried as a whole. Once the retries are used up, every event of the
+// batch is passed to the handler configured with WithErrorHandler and recorded as a dead
+// letter with a *BatchError. Drain and Close deliver pending batches right away.
+func (s *Engine) SubscribeBatch(eventName string, handler BatchHandler, settings BatchSettings) {
+	if settings.MaxSize <= 0 {
+		settings.MaxSize = 100
+	}
+	if settings.MaxWait <= 0 {
+		settings.MaxWait = time.Second
+	}
+	if settings.RetryBackoff <= 0 {
+		settings.RetryBackoff = 100 * time.Millisecond
+	}
+
+	b := &batcher{engine: s, eventName: eventName, handler: handler, settings: settings}
+	s.mu.Lock()
+	s.batchers = append(s.batchers, b)
+	s.mu.Unlock()
+
+	s.onDrain(func() { go b.flushAll() })
+	s.Subscribe(eventName, b.add)
+}
+
+// batcher collects the events of a batch subscription.
+type batcher struct {
+	engine    *Engine
+	eventName string
+	handler   BatchHandler
+	settings  BatchSettings
+
+	// flushMu serializes deliveries, so batches are delivered in order
+	flushMu  sync.Mutex
+	mu       sync.Mutex
+	events   []Event
+	deadline time.Time
+	timer    Timer
+}
+
+// add appends an event to the pending batch. Every pending event is registered with
+// beginDispatch, so that Drain waits for its batch.
+func (b *batcher) add(event Event) error {
+	event.Context = context.WithoutCancel(event.Context)
+	if err := b.engine.beginDispatch(); err != nil {
+		// The engine is draining, so there is nothing to wait for
+		b.deliver([]Event{event})
+		return nil
+	}
+
+	b.mu.Lock()
+	b.events = append(b.events, event)
+	if len(b.events) == 1 {
+		b.schedule()
+	}
+	full := len(b.events) >= b.settings.MaxSize
+	b.mu.Unlock()
+
+	if full {
+		go b.flush(false)
+	}
+	return nil
+}
+
+// schedule arms the timer for the batch that just started.
+func (b *batcher) schedule() {
+	b.deadline = b.engine.clock.Now().Add(b.settings.MaxWait)
+	if b.timer == nil {
+		b.timer = b.engine.clock.AfterFunc(b.settings.MaxWait, b.expire)
+	} else {
+		b.timer.Reset(b.settings.MaxWait)
+	}
+}
+
+// expire delivers the pending batch once its first event waited long enough.
+func (b *batcher) expire() {
+	b.mu.Lock()
+	if len(b.events) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	if wait := b.deadline.Sub(b.engine.clock.Now()); wait > 0 {
+		// The batch was delivered and a new one started since the timer was set
+		b.timer.Reset(wait)
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+
+	b.flush(true)
+}
+
+// flush delivers up to MaxSize of the oldest pending events and reports whether any were
+// delivered. Unless partial is set, it only delivers a full batch.
+func (b *batcher) flush(partial bool) bool {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	n := min(len(b.events), b.settings.MaxSize)
+	if !partial && n < b.settings.MaxSize {
+		n = 0
+	}
+	batch := b.events[:n:n]
+	b.events = b.events[n:]
+	if n > 0 && len(b.events) > 0 {
+		b.schedule()
+	}
+	b.mu.Unlock()
+
+	if n == 0 {
+		return false
+	}
+	b.deliver(batch)
+	for range batch {
+		b.engine.endDispatch()
+	}
+	return true
+}
+
+// flushAll delivers all pending events.
+func (b *batcher) flushAll() {
+	for b.flush(true) {
+	}
+}
+
+// deliver runs the handler for a batch, retrying it with exponential backoff.
+func (b *batcher) deliver(batch []Event) {
+	var err error
+	attempts := 0
+	backoff := b.settings.RetryBackoff
+	for {
+		attempts++
+		if err = b.handler(batch); err == nil {
+			return
+		}
+		if attempts > b.settings.MaxRetries {
+			break
+		}
+		sleep(b.engine.clock, backoff)
+		backoff *= 2
+	}
+
+	err = &BatchError{EventName: b.eventName, Size: len(batch), Attempts: attempts, Err: err}
+	for _, event := range batch {
+		b.engine.recordDeadLetter(event, err)
+		if b.engine.errorHandler != nil {
+			b.engine.errorHandler(event, err)
+		}
+	}
+}
+
+// flushBatches delivers the pending events of all batch subscriptions.
+func (s *Engine) flushBatches() {
+	s.mu.RLock()
+	batchers := s.batchers
+	s.mu.RUnlock()
+
+	for _, b := range batchers {
+		b.flushAll()
+	}
+}
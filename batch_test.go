@@ -0,0 +1,122 @@
+package beacon_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestSubscribeBatchSize(t *testing.T) {
+	engine := beacon.New()
+
+	var mu sync.Mutex
+	var batches [][]int
+	engine.SubscribeBatch("row.inserted", func(events []beacon.Event) error {
+		var rows []int
+		for _, e := range events {
+			rows = append(rows, e.Data.(int))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, rows)
+		return nil
+	}, beacon.BatchSettings{MaxSize: 3, MaxWait: time.Hour})
+
+	for i := range 7 {
+		if err := engine.Submit("row.inserted", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(batches) == 2
+	})
+
+	// Close delivers the incomplete batch
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 3 || len(batches[2]) != 1 || batches[2][0] != 6 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	for i, batch := range batches[:2] {
+		for j, row := range batch {
+			if row != i*3+j {
+				t.Errorf("unexpected batches: %v", batches)
+			}
+		}
+	}
+}
+
+func TestSubscribeBatchWait(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+
+	var batches [][]beacon.Event
+	engine.SubscribeBatch("row.inserted", func(events []beacon.Event) error {
+		batches = append(batches, events)
+		return nil
+	}, beacon.BatchSettings{MaxSize: 100, MaxWait: 20 * time.Millisecond})
+
+	engine.Submit("row.inserted", 1)
+	clock.Advance(10 * time.Millisecond)
+	engine.Submit("row.inserted", 2)
+
+	// MaxWait counts from the first event of the batch
+	clock.Advance(9 * time.Millisecond)
+	if len(batches) != 0 {
+		t.Fatalf("expected the batch to wait, got %d batches", len(batches))
+	}
+	clock.Advance(time.Millisecond)
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("expected a batch of 2 events after MaxWait, got %v", batches)
+	}
+}
+
+func TestSubscribeBatchRetry(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := beacon.NewMemoryDeadLetterStore(0)
+	failed := make(chan error, 2)
+	engine := beacon.New(
+		beacon.WithClock(clock),
+		beacon.WithDeadLetters(store),
+		beacon.WithErrorHandler(func(e beacon.Event, err error) { failed <- err }),
+	)
+
+	var attempts atomic.Int32
+	engine.SubscribeBatch("row.inserted", func(events []beacon.Event) error {
+		attempts.Add(1)
+		return errors.New("warehouse unavailable")
+	}, beacon.BatchSettings{MaxSize: 2, MaxWait: time.Hour, MaxRetries: 2, RetryBackoff: time.Second})
+
+	engine.Submit("row.inserted", 1)
+	engine.Submit("row.inserted", 2)
+
+	// The retries back off for one and then two seconds, next to the MaxWait timer
+	for i, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+		waitFor(t, func() bool { return clock.PendingTimers() == 2 })
+		clock.Advance(backoff - time.Millisecond)
+		if attempts.Load() != int32(i+1) {
+			t.Fatalf("retry %d did not back off", i+1)
+		}
+		clock.Advance(time.Millisecond)
+	}
+
+	var batchErr *beacon.BatchError
+	if err := <-failed; !errors.As(err, &batchErr) || batchErr.Size != 2 || batchErr.Attempts != 3 {
+		t.Errorf("expected BatchError, got %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts.Load())
+	}
+	waitFor(t, func() bool { return len(engine.DeadLetters()) == 2 })
+}
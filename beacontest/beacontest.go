@@ -0,0 +1,93 @@
+// Package beacontest provides helpers for testing code that uses beacon.
+package beacontest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufconnSize is the buffer size of in-memory listeners.
+const bufconnSize = 1024 * 1024
+
+// bufconnCount makes the targets of in-memory connections unique.
+var bufconnCount atomic.Uint64
+
+// ServeBufconn serves the event service of an engine on an in-memory listener and returns a
+// client connection to it. The server and connection are stopped when the test finishes.
+func ServeBufconn(t testing.TB, engine *beacon.Engine, opts ...beacon.ServiceOption) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(bufconnSize)
+	serve(t, lis, engine, opts...)
+
+	target := fmt.Sprintf("passthrough:///bufconn-%d", bufconnCount.Add(1))
+	conn, err := grpc.NewClient(target,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// ServeUnix serves the event service of an engine on a Unix domain socket in a temporary
+// directory and returns a client connection to it. The server and connection are stopped
+// when the test finishes.
+func ServeUnix(t testing.TB, engine *beacon.Engine, opts ...beacon.ServiceOption) *grpc.ClientConn {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "beacon.sock")
+	lis, err := beacon.ListenUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve(t, lis, engine, opts...)
+
+	conn, err := beacon.DialUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// NewBufconnPair returns a server engine and a client engine submitting events to it over an in-memory connection.
+func NewBufconnPair(t testing.TB, opts ...beacon.ServiceOption) (client, server *beacon.Engine) {
+	t.Helper()
+
+	server = beacon.New()
+	client = beacon.New(beacon.WithRemote(ServeBufconn(t, server, opts...)))
+	return client, server
+}
+
+// NewUnixPair returns a server engine and a client engine submitting events to it over a Unix domain socket.
+func NewUnixPair(t testing.TB, opts ...beacon.ServiceOption) (client, server *beacon.Engine) {
+	t.Helper()
+
+	server = beacon.New()
+	client = beacon.New(beacon.WithRemote(ServeUnix(t, server, opts...)))
+	return client, server
+}
+
+func serve(t testing.TB, lis net.Listener, engine *beacon.Engine, opts ...beacon.ServiceOption) {
+	s := grpc.NewServer()
+	beacon.RegisterEventService(s, engine, opts...)
+
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+}
@@ -0,0 +1,35 @@
+package beacontest_test
+
+import (
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestPairs(t *testing.T) {
+	pairs := map[string]func(testing.TB, ...beacon.ServiceOption) (*beacon.Engine, *beacon.Engine){
+		"bufconn": beacontest.NewBufconnPair,
+		"unix":    beacontest.NewUnixPair,
+	}
+
+	for name, newPair := range pairs {
+		t.Run(name, func(t *testing.T) {
+			client, server := newPair(t)
+
+			message := ""
+			server.Subscribe("test", func(e beacon.Event) error {
+				message = e.Data.(string)
+				return nil
+			})
+
+			if err := client.Submit("test", "hello world"); err != nil {
+				t.Fatal(err)
+			}
+
+			if message != "hello world" {
+				t.Errorf("unexpected message: %s", message)
+			}
+		})
+	}
+}
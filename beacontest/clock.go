@@ -0,0 +1,162 @@
+package beacontest
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+)
+
+// Clock is a fake beacon.Clock for tests. Its time only moves when it is advanced, which
+// fires the timers that became due one after another at the time they were due.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewClock creates a fake clock starting at the given time.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc calls f once the clock was advanced by the duration. Unless the duration is
+// zero or less, f runs in the goroutine advancing the clock.
+func (c *Clock) AfterFunc(d time.Duration, f func()) beacon.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, f: f}
+	t.schedule(d)
+	return t
+}
+
+// Advance moves the clock forward by the duration like a real clock would: it steps to
+// the time each timer is due and fires it, earliest first, including timers armed by the
+// functions of timers fired before. It returns once their functions returned.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		t := c.next(target)
+		if t == nil {
+			break
+		}
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		t.unschedule()
+
+		// The function runs without the lock, so it may arm timers again
+		c.mu.Unlock()
+		t.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// Jump moves the clock forward by the duration at once, like a machine resuming from
+// suspension, and fires the timers that became due in between late, at the new time and
+// earliest first. It returns once their functions returned.
+func (c *Clock) Jump(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	c.timers = slices.DeleteFunc(c.timers, func(t *fakeTimer) bool {
+		if t.when.After(c.now) {
+			return false
+		}
+		due = append(due, t)
+		return true
+	})
+	c.mu.Unlock()
+
+	slices.SortStableFunc(due, func(a, b *fakeTimer) int { return a.when.Compare(b.when) })
+	for _, t := range due {
+		t.f()
+	}
+}
+
+// next returns the earliest timer due by the given time, or nil if there is none. Timers
+// due at the same time are returned in the order they were armed. The clock must be locked.
+func (c *Clock) next(by time.Time) *fakeTimer {
+	var next *fakeTimer
+	for _, t := range c.timers {
+		if !t.when.After(by) && (next == nil || t.when.Before(next.when)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// Set moves the clock forward to the given time like Advance. Times before the current
+// time of the clock are ignored.
+func (c *Clock) Set(now time.Time) {
+	if d := now.Sub(c.Now()); d > 0 {
+		c.Advance(d)
+	}
+}
+
+// PendingTimers returns the number of timers that have not fired yet. Tests use it to wait
+// until the code under test armed a timer before advancing the clock.
+func (c *Clock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// fakeTimer is a timer of a fake clock.
+type fakeTimer struct {
+	clock *Clock
+	when  time.Time
+	f     func()
+}
+
+// schedule adds the timer to the clock, which must be locked. Like timers of the time
+// package, a timer without a positive duration fires right away.
+func (t *fakeTimer) schedule(d time.Duration) {
+	if d <= 0 {
+		go t.f()
+		return
+	}
+	t.when = t.clock.now.Add(d)
+	t.clock.timers = append(t.clock.timers, t)
+}
+
+// unschedule removes the timer from the clock, which must be locked, and reports whether
+// it was pending.
+func (t *fakeTimer) unschedule() bool {
+	i := slices.Index(t.clock.timers, t)
+	if i < 0 {
+		return false
+	}
+	t.clock.timers = slices.Delete(t.clock.timers, i, i+1)
+	return true
+}
+
+// Stop prevents the timer from firing and reports whether it was still pending.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.unschedule()
+}
+
+// Reset changes the timer to fire once the clock was advanced by the duration and reports
+// whether it was still pending.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	pending := t.unschedule()
+	t.schedule(d)
+	return pending
+}
@@ -0,0 +1,116 @@
+package beacontest_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestClockTimers(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := beacontest.NewClock(start)
+
+	fired := make(chan string, 3)
+	clock.AfterFunc(time.Minute, func() { fired <- "minute" })
+	stopped := clock.AfterFunc(time.Second, func() { fired <- "stopped" })
+	reset := clock.AfterFunc(time.Second, func() { fired <- "reset" })
+
+	if !stopped.Stop() {
+		t.Error("expected timer to be pending")
+	}
+	reset.Reset(time.Hour)
+
+	clock.Advance(time.Minute)
+	if got := <-fired; got != "minute" {
+		t.Errorf("expected minute timer to fire, got %s", got)
+	}
+	if clock.PendingTimers() != 1 {
+		t.Errorf("expected 1 pending timer, got %d", clock.PendingTimers())
+	}
+
+	clock.Set(start.Add(time.Minute + time.Hour))
+	if got := <-fired; got != "reset" {
+		t.Errorf("expected reset timer to fire, got %s", got)
+	}
+	if !clock.Now().Equal(start.Add(time.Minute + time.Hour)) {
+		t.Errorf("unexpected time: %v", clock.Now())
+	}
+}
+
+func TestClockOrder(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var fired []string
+	clock.AfterFunc(3*time.Second, func() { fired = append(fired, "third") })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, "first") })
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, "second") })
+
+	// The timers have fired once Advance returns
+	clock.Advance(time.Minute)
+	if !slices.Equal(fired, []string{"first", "second", "third"}) {
+		t.Errorf("unexpected order: %v", fired)
+	}
+}
+
+func TestClockSteps(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := beacontest.NewClock(start)
+
+	// A timer arming itself again fires at every second within a single Advance
+	var fired []time.Time
+	var tick func()
+	tick = func() {
+		fired = append(fired, clock.Now())
+		clock.AfterFunc(time.Second, tick)
+	}
+	clock.AfterFunc(time.Second, tick)
+
+	clock.Advance(3*time.Second + time.Millisecond)
+	expected := []time.Time{start.Add(time.Second), start.Add(2 * time.Second), start.Add(3 * time.Second)}
+	if !slices.Equal(fired, expected) {
+		t.Errorf("expected timer to fire at %v, got %v", expected, fired)
+	}
+	if !clock.Now().Equal(start.Add(3*time.Second + time.Millisecond)) {
+		t.Errorf("unexpected time: %v", clock.Now())
+	}
+
+	// Jump fires the timer once, late
+	fired = nil
+	clock.Jump(time.Hour)
+	if len(fired) != 1 || !fired[0].Equal(clock.Now()) {
+		t.Errorf("expected timer to fire once at %v, got %v", clock.Now(), fired)
+	}
+}
+
+func TestClockEngine(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := beacontest.NewClock(start)
+	engine := beacon.New(beacon.WithClock(clock))
+
+	fired := make(chan beacon.Event, 1)
+	engine.Subscribe("reminder", func(e beacon.Event) error {
+		fired <- e
+		return nil
+	})
+
+	if _, err := engine.SubmitAfter(context.Background(), 24*time.Hour, "reminder", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(23 * time.Hour)
+	select {
+	case <-fired:
+		t.Fatal("event fired too early")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Hour)
+	e := <-fired
+	if !e.Timestamp.Equal(start.Add(24 * time.Hour)) {
+		t.Errorf("unexpected timestamp: %v", e.Timestamp)
+	}
+}
@@ -0,0 +1,175 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for handlers skipped because their circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all events through to the handler.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen short-circuits the handler until the open timeout has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets single trial events through to find out if the handler recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreakerSettings configures the circuit breaker of a handler.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before trial events are let through.
+	// Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// SuccessThreshold is the number of consecutive successful trial events closing the
+	// circuit again. Defaults to 1.
+	SuccessThreshold int
+	// Skip passes over the handler while the circuit is open, as if it succeeded. By default
+	// the handler fails with ErrCircuitOpen instead.
+	Skip bool
+}
+
+// CircuitStateChange is submitted to the engine with AsEvent whenever the circuit breaker
+// of a handler changes its state.
+type CircuitStateChange struct {
+	EventName string
+	// Handler is the name given to the handler with HandlerName, if any.
+	Handler string
+	From    CircuitState
+	To      CircuitState
+	// Err is the error that opened the circuit, if any.
+	Err error
+}
+
+// CircuitBreaker stops calling a handler that keeps failing, e.g. because a downstream
+// dependency is down. Once the handler failed FailureThreshold times in a row, the circuit
+// opens and the handler is short-circuited until OpenTimeout has passed. Then single trial
+// events are let through, closing the circuit again after SuccessThreshold successes.
+// State changes are submitted to the engine as CircuitStateChange events.
+func CircuitBreaker(settings CircuitBreakerSettings) SubscribeOption {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.SuccessThreshold <= 0 {
+		settings.SuccessThreshold = 1
+	}
+	return func(sub *subscription) {
+		sub.breaker = &circuitBreaker{settings: settings}
+	}
+}
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+	clock    Clock
+	// notify is called with state changes outside of the lock.
+	notify func(CircuitStateChange)
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	openedAt  time.Time
+	trial     bool
+}
+
+// allow reports whether an event may be passed to the handler.
+func (b *circuitBreaker) allow(eventName, handler string) bool {
+	b.mu.Lock()
+	var change *CircuitStateChange
+	defer func() {
+		b.mu.Unlock()
+		b.emit(change)
+	}()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.clock.Now().Sub(b.openedAt) < b.settings.OpenTimeout {
+			return false
+		}
+		change = b.transition(CircuitHalfOpen, eventName, handler, nil)
+		fallthrough
+	case CircuitHalfOpen:
+		if b.trial {
+			return false // Only one trial event at a time
+		}
+		b.trial = true
+	}
+	return true
+}
+
+// record updates the breaker with the outcome of an event passed to the handler.
+func (b *circuitBreaker) record(eventName, handler string, err error) {
+	b.mu.Lock()
+	var change *CircuitStateChange
+	defer func() {
+		b.mu.Unlock()
+		b.emit(change)
+	}()
+
+	halfOpen := b.state == CircuitHalfOpen
+	if halfOpen {
+		b.trial = false
+	}
+
+	if err != nil {
+		b.successes = 0
+		b.failures++
+		if halfOpen || (b.state == CircuitClosed && b.failures >= b.settings.FailureThreshold) {
+			b.openedAt = b.clock.Now()
+			change = b.transition(CircuitOpen, eventName, handler, err)
+		}
+		return
+	}
+
+	b.failures = 0
+	if halfOpen {
+		b.successes++
+		if b.successes >= b.settings.SuccessThreshold {
+			b.successes = 0
+			change = b.transition(CircuitClosed, eventName, handler, nil)
+		}
+	}
+}
+
+// transition changes the state and returns the change to emit. It must be called with mu held.
+func (b *circuitBreaker) transition(to CircuitState, eventName, handler string, err error) *CircuitStateChange {
+	change := &CircuitStateChange{EventName: eventName, Handler: handler, From: b.state, To: to, Err: err}
+	b.state = to
+	return change
+}
+
+func (b *circuitBreaker) emit(change *CircuitStateChange) {
+	if change != nil && b.notify != nil {
+		b.notify(*change)
+	}
+}
+
+// notifyCircuitChange submits a circuit state change to the handlers of the engine.
+func (s *Engine) notifyCircuitChange(change CircuitStateChange) {
+	name, data := AsEvent(change)
+	s.fireEvent(name, s.newEvent(context.Background(), name, data))
+}
@@ -0,0 +1,82 @@
+package beacon_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+
+	var changes []beacon.CircuitStateChange
+	engine.Subscribe(beacon.Wrap(func(change beacon.CircuitStateChange) error {
+		changes = append(changes, change)
+		return nil
+	}))
+
+	down := true
+	calls := 0
+	engine.Subscribe("invoice.sent", func(e beacon.Event) error {
+		calls++
+		if down {
+			return errors.New("mail server unavailable")
+		}
+		return nil
+	}, beacon.HandlerName("mailer"), beacon.CircuitBreaker(beacon.CircuitBreakerSettings{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+	}))
+
+	for range 2 {
+		engine.Submit("invoice.sent", nil)
+	}
+	if err := engine.Submit("invoice.sent", nil); !errors.Is(err, beacon.ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected handler to be short-circuited, got %d calls", calls)
+	}
+
+	clock.Advance(20 * time.Millisecond)
+	down = false
+	if err := engine.Submit("invoice.sent", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []beacon.CircuitState{beacon.CircuitOpen, beacon.CircuitHalfOpen, beacon.CircuitClosed}
+	if len(changes) != len(expected) {
+		t.Fatalf("unexpected state changes: %+v", changes)
+	}
+	for i, change := range changes {
+		if change.To != expected[i] || change.Handler != "mailer" || change.EventName != "invoice.sent" {
+			t.Errorf("unexpected state change %d: %+v", i, change)
+		}
+	}
+}
+
+func TestCircuitBreakerSkip(t *testing.T) {
+	engine := beacon.New()
+
+	engine.Subscribe("test", func(e beacon.Event) error {
+		return errors.New("some error message")
+	}, beacon.CircuitBreaker(beacon.CircuitBreakerSettings{FailureThreshold: 1, Skip: true}))
+
+	ran := false
+	engine.Subscribe("test", func(e beacon.Event) error {
+		ran = true
+		return nil
+	})
+
+	engine.Submit("test", nil)
+	if err := engine.Submit("test", nil); err != nil {
+		t.Errorf("expected open circuit to be skipped, got %v", err)
+	}
+	if !ran {
+		t.Error("handler after the skipped handler did not run")
+	}
+}
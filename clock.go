@@ -0,0 +1,92 @@
+package beacon
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and runs timers for the engine. Tests replace it with a fake clock
+// like the one of the beacontest package to control event timestamps and everything
+// time-based, like delays, debouncing, batching and cron schedules.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine once the duration has elapsed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock. It is implemented by *time.Timer.
+type Timer interface {
+	// Stop prevents the timer from firing and reports whether it was still pending.
+	Stop() bool
+	// Reset changes the timer to fire after the duration and reports whether it was
+	// still pending.
+	Reset(d time.Duration) bool
+}
+
+// RealClock is the Clock of the time package, used by default.
+type RealClock struct{}
+
+// Now returns the current time.
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc calls f in its own goroutine once the duration has elapsed.
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock makes the engine use the clock for event timestamps and everything
+// time-based. Event services of the engine use it as well.
+func WithClock(clock Clock) Option {
+	return func(engine *Engine) {
+		engine.clock = clock
+	}
+}
+
+// clockUser is implemented by types the engine hands its clock to, like LRUDedupStore.
+type clockUser interface {
+	useClock(clock Clock)
+}
+
+// sleep waits for the duration on the clock.
+func sleep(clock Clock, d time.Duration) {
+	done := make(chan struct{})
+	clock.AfterFunc(d, func() { close(done) })
+	<-done
+}
+
+// tick sends the time of the clock on the returned channel every time the duration has
+// elapsed, until stop is called. Like a time.Ticker, it drops ticks for a slow receiver.
+func tick(clock Clock, d time.Duration) (ticks <-chan time.Time, stop func()) {
+	c := make(chan time.Time, 1)
+	var mu sync.Mutex
+	var timer Timer
+	stopped := false
+
+	var fire func()
+	fire = func() {
+		select {
+		case c <- clock.Now():
+		default:
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			timer.Reset(d)
+		}
+	}
+
+	mu.Lock()
+	timer = clock.AfterFunc(d, fire)
+	mu.Unlock()
+
+	return c, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		timer.Stop()
+	}
+}
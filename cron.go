@@ -0,0 +1,209 @@
+package beacon
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// MissedRunPolicy decides which events a cron schedule fires for runs that became due
+// while the engine could not fire them, e.g. because the machine was suspended.
+type MissedRunPolicy int
+
+const (
+	// FireLatestRun fires a single event for the latest of the runs that became due at
+	// once. It is the default.
+	FireLatestRun MissedRunPolicy = iota
+	// FireAllRuns fires an event for every run that became due, oldest first.
+	FireAllRuns
+	// SkipMissedRuns fires no event when several runs became due at once, waiting for the
+	// next run instead.
+	SkipMissedRuns
+)
+
+// maxMissedRuns limits how many events FireAllRuns fires at once.
+const maxMissedRuns = 1000
+
+// CronTick is the data of the events fired by a cron schedule.
+type CronTick struct {
+	// Spec is the schedule that fired the event.
+	Spec string
+	// ScheduledAt is the time the run was due, before any jitter.
+	ScheduledAt time.Time
+	// Missed is the number of earlier runs skipped in favor of this one.
+	Missed int
+}
+
+// CronOption is a functional option for configuring a cron schedule.
+type CronOption func(*cronJob)
+
+// CronLocation evaluates the cron expression in the given time zone. By default the local
+// time zone is used.
+func CronLocation(loc *time.Location) CronOption {
+	return func(job *cronJob) {
+		job.location = loc
+	}
+}
+
+// CronMissedRuns configures which events are fired for missed runs.
+func CronMissedRuns(policy MissedRunPolicy) CronOption {
+	return func(job *cronJob) {
+		job.policy = policy
+	}
+}
+
+// CronJitter delays every run by a random duration up to jitter, so that many engines
+// sharing a schedule do not fire at the same instant.
+func CronJitter(jitter time.Duration) CronOption {
+	return func(job *cronJob) {
+		job.jitter = jitter
+	}
+}
+
+// Cron fires an event with the given name on a schedule and returns a function stopping
+// it. The data of the events is a CronTick. The spec is either a cron expression with the
+// five fields minute, hour, day of month, month and day of week, like "0 3 * * *", one of
+// @yearly, @monthly, @weekly, @daily, @midnight and @hourly, or "@every" followed by a
+// duration, like "@every 5m".
+//
+// The events are dispatched like events submitted with SubmitAsync, so their errors are
+// passed to the handler configured with WithErrorHandler. Close stops all schedules, and
+// Cron fails with ErrClosed afterwards.
+func (s *Engine) Cron(spec, eventName string, opts ...CronOption) (func(), error) {
+	if eventName == "" {
+		return nil, ErrEventNameRequired
+	}
+	schedule, err := parseCronSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &cronJob{engine: s, spec: spec, eventName: eventName, schedule: schedule, location: time.Local}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	s.scheduleMu.Lock()
+	if s.scheduleStopped {
+		s.scheduleMu.Unlock()
+		return nil, ErrClosed
+	}
+	s.cronJobs = append(s.cronJobs, job)
+	s.scheduleMu.Unlock()
+
+	job.start()
+	return job.stop, nil
+}
+
+// cronJob fires the events of a cron schedule.
+type cronJob struct {
+	engine    *Engine
+	spec      string
+	eventName string
+	schedule  cronSchedule
+	location  *time.Location
+	policy    MissedRunPolicy
+	jitter    time.Duration
+
+	mu      sync.Mutex
+	next    time.Time
+	timer   Timer
+	stopped bool
+}
+
+// start arms the timer for the first run.
+func (job *cronJob) start() {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	job.next = job.schedule.next(job.engine.clock.Now().In(job.location))
+	job.arm()
+}
+
+// arm sets the timer to the next run.
+func (job *cronJob) arm() {
+	if job.stopped || job.next.IsZero() {
+		return
+	}
+
+	wait := job.next.Sub(job.engine.clock.Now())
+	if job.jitter > 0 {
+		wait += rand.N(job.jitter)
+	}
+	if job.timer == nil {
+		job.timer = job.engine.clock.AfterFunc(wait, job.run)
+	} else {
+		job.timer.Reset(wait)
+	}
+}
+
+// stop stops firing events.
+func (job *cronJob) stop() {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	job.stopped = true
+	if job.timer != nil {
+		job.timer.Stop()
+	}
+}
+
+// run fires the events of the runs that are due and arms the timer for the next run.
+func (job *cronJob) run() {
+	job.mu.Lock()
+	if job.stopped {
+		job.mu.Unlock()
+		return
+	}
+
+	now := job.engine.clock.Now().In(job.location)
+	if now.Before(job.next) {
+		// The timer fired early, e.g. after the wall clock was set back
+		job.arm()
+		job.mu.Unlock()
+		return
+	}
+
+	var due []time.Time
+	for t := job.next; !t.IsZero() && !t.After(now); t = job.schedule.next(t) {
+		due = append(due, t)
+		if len(due) > maxMissedRuns {
+			due = due[1:]
+		}
+	}
+	job.next = job.schedule.next(due[len(due)-1])
+	job.arm()
+	job.mu.Unlock()
+
+	missed := len(due) - 1
+	switch job.policy {
+	case FireAllRuns:
+		for _, t := range due {
+			job.fire(CronTick{Spec: job.spec, ScheduledAt: t})
+		}
+	case SkipMissedRuns:
+		if missed == 0 {
+			job.fire(CronTick{Spec: job.spec, ScheduledAt: due[0]})
+		}
+	default:
+		job.fire(CronTick{Spec: job.spec, ScheduledAt: due[missed], Missed: missed})
+	}
+}
+
+// fire dispatches the event of a run.
+func (job *cronJob) fire(tick CronTick) {
+	job.engine.submitDetached(job.engine.newEvent(context.Background(), job.eventName, tick), nil)
+}
+
+// stopCron stops all cron schedules.
+func (s *Engine) stopCron() {
+	s.scheduleMu.Lock()
+	jobs := s.cronJobs
+	s.cronJobs = nil
+	s.scheduleMu.Unlock()
+
+	for _, job := range jobs {
+		job.stop()
+	}
+}
@@ -0,0 +1,199 @@
+package beacon_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestCronEvery(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := beacontest.NewClock(start)
+	engine := beacon.New(beacon.WithClock(clock))
+	defer engine.Close()
+
+	ticks := make(chan beacon.CronTick, 8)
+	engine.Subscribe("cleanup", func(e beacon.Event) error {
+		ticks <- e.Data.(beacon.CronTick)
+		return nil
+	})
+
+	stop, err := engine.Cron("@every 10ms", "cleanup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 3 {
+		clock.Advance(10 * time.Millisecond)
+		select {
+		case tick := <-ticks:
+			if expected := start.Add(time.Duration(i+1) * 10 * time.Millisecond); tick.Spec != "@every 10ms" || !tick.ScheduledAt.Equal(expected) {
+				t.Errorf("unexpected tick: %+v", tick)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("cron did not fire")
+		}
+	}
+
+	stop()
+	if clock.PendingTimers() != 0 {
+		t.Error("expected stopped cron to release its timer")
+	}
+}
+
+func TestCronInvalidSpec(t *testing.T) {
+	engine := beacon.New()
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"@every",
+		"@every -1m",
+		"@fortnightly",
+	} {
+		if _, err := engine.Cron(spec, "job"); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+
+	for _, spec := range []string{
+		"0 3 * * *",
+		"*/15 9-17 * * mon-fri",
+		"0 0 1,15 jan,jul *",
+		"30 4 * * 7",
+		"@daily",
+		"@every 1h30m",
+	} {
+		stop, err := engine.Cron(spec, "job", beacon.CronLocation(time.UTC))
+		if err != nil {
+			t.Errorf("%q: %v", spec, err)
+			continue
+		}
+		stop()
+	}
+}
+
+func TestCronClosed(t *testing.T) {
+	engine := beacon.New()
+	engine.Close()
+
+	if _, err := engine.Cron("@hourly", "report"); !errors.Is(err, beacon.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestCronLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// 02:30 in Berlin
+	clock := beacontest.NewClock(time.Date(2026, 1, 5, 1, 30, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+	defer engine.Close()
+
+	ticks := make(chan beacon.CronTick, 1)
+	engine.Subscribe("backup", func(e beacon.Event) error {
+		ticks <- e.Data.(beacon.CronTick)
+		return nil
+	})
+	if _, err := engine.Cron("0 3 * * mon-fri", "backup", beacon.CronLocation(berlin)); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(30 * time.Minute)
+	tick := <-ticks
+	if expected := time.Date(2026, 1, 5, 2, 0, 0, 0, time.UTC); !tick.ScheduledAt.Equal(expected) {
+		t.Errorf("expected run at %v, got %v", expected, tick.ScheduledAt)
+	}
+
+	// The next run is on Tuesday, Saturday and Sunday are skipped later on
+	waitFor(t, func() bool { return clock.PendingTimers() == 1 })
+	clock.Advance(24 * time.Hour)
+	if tick := <-ticks; tick.ScheduledAt.Weekday() != time.Tuesday {
+		t.Errorf("expected run on Tuesday, got %v", tick.ScheduledAt)
+	}
+}
+
+func TestCronAdvance(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+	defer engine.Close()
+
+	ticks := make(chan beacon.CronTick, 8)
+	engine.Subscribe("heartbeat", func(e beacon.Event) error {
+		ticks <- e.Data.(beacon.CronTick)
+		return nil
+	})
+	if _, err := engine.Cron("@every 1s", "heartbeat"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Advancing the clock runs the schedule like time passing, without missed runs
+	clock.Advance(5 * time.Second)
+	for i := range 5 {
+		select {
+		case tick := <-ticks:
+			if tick.Missed != 0 {
+				t.Errorf("unexpected missed runs: %+v", tick)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected 5 runs, got %d", i)
+		}
+	}
+}
+
+func TestCronMissedRuns(t *testing.T) {
+	tests := []struct {
+		policy beacon.MissedRunPolicy
+		ticks  []int // Missed of the expected ticks
+	}{
+		{beacon.FireLatestRun, []int{2}},
+		{beacon.FireAllRuns, []int{0, 0, 0}},
+		{beacon.SkipMissedRuns, nil},
+	}
+
+	for _, test := range tests {
+		clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		engine := beacon.New(beacon.WithClock(clock))
+
+		ticks := make(chan beacon.CronTick, 8)
+		engine.Subscribe("report", func(e beacon.Event) error {
+			ticks <- e.Data.(beacon.CronTick)
+			return nil
+		})
+		if _, err := engine.Cron("@hourly", "report", beacon.CronMissedRuns(test.policy), beacon.CronLocation(time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+
+		// The machine was suspended for three hours
+		clock.Jump(3 * time.Hour)
+
+		var missed []int
+	collect:
+		for {
+			select {
+			case tick := <-ticks:
+				missed = append(missed, tick.Missed)
+			case <-time.After(50 * time.Millisecond):
+				break collect
+			}
+		}
+		if !slices.Equal(missed, test.ticks) {
+			t.Errorf("policy %d: expected ticks %v, got %v", test.policy, test.ticks, missed)
+		}
+		engine.Close()
+	}
+}
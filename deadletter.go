@@ -0,0 +1,146 @@
+package beacon
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeadLetter is an event whose handlers failed.
+type DeadLetter struct {
+	// ID identifies the dead letter in its store. It is assigned by the store.
+	ID       string
+	Event    Event
+	Err      error
+	FailedAt time.Time
+}
+
+// DeadLetterStore keeps events whose handlers failed so they can be replayed.
+type DeadLetterStore interface {
+	// Add records a failed event and returns the ID assigned to its dead letter. A letter
+	// that already has an ID replaces the dead letter with that ID.
+	Add(letter DeadLetter) string
+	// List returns the dead letters, oldest first.
+	List() []DeadLetter
+	// Remove deletes a dead letter by its ID and reports whether it was present.
+	Remove(id string) (DeadLetter, bool)
+}
+
+// WithDeadLetters records events whose handlers failed in the store. They can be
+// inspected with DeadLetters and dispatched again with Replay.
+func WithDeadLetters(store DeadLetterStore) Option {
+	return func(engine *Engine) {
+		engine.deadLetters = store
+	}
+}
+
+// MemoryDeadLetterStore is an in-memory DeadLetterStore bounded in size.
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	size    int
+	nextID  uint64
+	letters []DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates a DeadLetterStore that keeps the last size dead letters.
+// A size of zero keeps all of them.
+func NewMemoryDeadLetterStore(size int) *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{size: size}
+}
+
+// Add records a failed event and returns the ID assigned to its dead letter. A letter
+// that already has an ID replaces the dead letter with that ID.
+func (s *MemoryDeadLetterStore) Add(letter DeadLetter) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if letter.ID == "" {
+		s.nextID++
+		letter.ID = strconv.FormatUint(s.nextID, 10)
+	} else {
+		s.remove(letter.ID)
+	}
+	s.letters = append(s.letters, letter)
+	if s.size > 0 && len(s.letters) > s.size {
+		s.letters = append([]DeadLetter(nil), s.letters[len(s.letters)-s.size:]...)
+	}
+	return letter.ID
+}
+
+// List returns the dead letters, oldest first.
+func (s *MemoryDeadLetterStore) List() []DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.letters...)
+}
+
+// Remove deletes a dead letter by its ID and reports whether it was present.
+func (s *MemoryDeadLetterStore) Remove(id string) (DeadLetter, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(id)
+}
+
+func (s *MemoryDeadLetterStore) remove(id string) (DeadLetter, bool) {
+	for i, letter := range s.letters {
+		if letter.ID == id {
+			s.letters = append(s.letters[:i:i], s.letters[i+1:]...)
+			return letter, true
+		}
+	}
+	return DeadLetter{}, false
+}
+
+// DeadLetters returns the events whose handlers failed, or nil without WithDeadLetters.
+func (s *Engine) DeadLetters() []DeadLetter {
+	if s.deadLetters == nil {
+		return nil
+	}
+	return s.deadLetters.List()
+}
+
+// Replay dispatches dead letters again and returns how many were handled successfully.
+// The IDs are those of the dead letters, not of their events. Without IDs all dead
+// letters are replayed. Events failing again are put back into the
+// store. Replaying stops early when ctx is done.
+func (s *Engine) Replay(ctx context.Context, ids ...string) (int, error) {
+	if s.deadLetters == nil {
+		return 0, nil
+	}
+	if len(ids) == 0 {
+		for _, letter := range s.deadLetters.List() {
+			ids = append(ids, letter.ID)
+		}
+	}
+
+	replayed := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		letter, ok := s.deadLetters.Remove(id)
+		if !ok {
+			continue
+		}
+		result, err := s.fireEvent(letter.Event.Name, letter.Event)
+		switch {
+		case err == nil:
+			replayed++
+		case len(result.Errors) == 0:
+			// The event was not dispatched, e.g. because the engine is draining
+			s.deadLetters.Add(letter)
+		}
+	}
+	return replayed, nil
+}
+
+// recordDeadLetter adds an event whose handlers failed to the dead letter store.
+func (s *Engine) recordDeadLetter(event Event, err error) {
+	if s.deadLetters == nil {
+		return
+	}
+	event.Context = context.WithoutCancel(event.Context)
+	s.deadLetters.Add(DeadLetter{Event: event, Err: err, FailedAt: s.clock.Now()})
+}
@@ -0,0 +1,191 @@
+package beacon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Burst is the data of the event delivered to a handler subscribed with DeliverBurst. It
+// holds all events of the burst in the order they were submitted.
+type Burst []Event
+
+// deferral configures how the events of a subscription are held back and coalesced.
+type deferral struct {
+	wait     time.Duration
+	throttle bool
+	key      func(Event) string
+	burst    bool
+}
+
+// deferralOf returns the deferral of a subscription, adding one if needed.
+func deferralOf(sub *subscription) *deferral {
+	if sub.deferral == nil {
+		sub.deferral = &deferral{}
+	}
+	return sub.deferral
+}
+
+// Debounce delays the handler until no further events arrived for the wait duration, then
+// delivers the last of them. Submitting an event returns right away, errors of the handler
+// are passed to the handler configured with WithErrorHandler.
+func Debounce(wait time.Duration) SubscribeOption {
+	return func(sub *subscription) {
+		d := deferralOf(sub)
+		d.wait = wait
+		d.throttle = false
+	}
+}
+
+// Throttle runs the handler at most once per interval. The first event is delivered right
+// away, the last of the events arriving during the interval when it ends. Errors of these
+// trailing deliveries are passed to the handler configured with WithErrorHandler.
+func Throttle(interval time.Duration) SubscribeOption {
+	return func(sub *subscription) {
+		d := deferralOf(sub)
+		d.wait = interval
+		d.throttle = true
+	}
+}
+
+// CoalesceBy debounces or throttles events separately per key, e.g. per order ID. It is
+// used together with Debounce or Throttle.
+func CoalesceBy(key func(e Event) string) SubscribeOption {
+	return func(sub *subscription) {
+		deferralOf(sub).key = key
+	}
+}
+
+// DeliverBurst delivers all events coalesced by Debounce or Throttle instead of only the
+// last one. The handler receives the last event with its data replaced by the Burst.
+func DeliverBurst() SubscribeOption {
+	return func(sub *subscription) {
+		deferralOf(sub).burst = true
+	}
+}
+
+// pendingBurst holds the events coalesced under one key.
+type pendingBurst struct {
+	events   []Event
+	index    int
+	deadline time.Time
+	timer    Timer
+}
+
+// deferrer debounces or throttles the events of a subscription.
+type deferrer struct {
+	*deferral
+	engine *Engine
+	sub    *subscription
+
+	mu     sync.Mutex
+	bursts map[string]*pendingBurst
+}
+
+// newDeferrer returns the deferrer of a subscription, which delivers pending events once
+// the engine starts draining.
+func newDeferrer(engine *Engine, sub *subscription) *deferrer {
+	d := &deferrer{deferral: sub.deferral, engine: engine, sub: sub, bursts: map[string]*pendingBurst{}}
+	engine.onDrain(d.flush)
+	return d
+}
+
+// add holds an event back until it is due. Pending bursts are registered with
+// beginDispatch, so that Drain waits for them.
+func (d *deferrer) add(event Event, index int) error {
+	key := ""
+	if d.key != nil {
+		key = d.key(event)
+	}
+	event.Context = context.WithoutCancel(event.Context)
+
+	d.mu.Lock()
+	b, ok := d.bursts[key]
+	if !ok {
+		if err := d.engine.beginDispatch(); err != nil {
+			// The engine is draining, so there is nothing to wait for
+			d.mu.Unlock()
+			return d.sub.callNow(event, index)
+		}
+		b = &pendingBurst{deadline: d.engine.clock.Now().Add(d.wait)}
+		b.timer = d.engine.clock.AfterFunc(d.wait, func() { d.expire(key, b) })
+		d.bursts[key] = b
+
+		if d.throttle {
+			// The leading event of an interval is delivered right away
+			d.mu.Unlock()
+			return d.sub.callNow(event, index)
+		}
+	}
+
+	b.events = append(b.events, event)
+	b.index = index
+	if !d.throttle {
+		b.deadline = d.engine.clock.Now().Add(d.wait)
+	}
+	d.mu.Unlock()
+	return nil
+}
+
+// expire delivers the events of a burst once its deadline passed. A throttled burst
+// starts a new interval if events arrived during the last one.
+func (d *deferrer) expire(key string, b *pendingBurst) {
+	d.mu.Lock()
+	if d.bursts[key] != b {
+		// The burst was flushed in the meantime
+		d.mu.Unlock()
+		return
+	}
+	if wait := b.deadline.Sub(d.engine.clock.Now()); wait > 0 {
+		// More events were debounced since the timer was set
+		b.timer.Reset(wait)
+		d.mu.Unlock()
+		return
+	}
+
+	events, index := b.events, b.index
+	b.events = nil
+	if d.throttle && len(events) > 0 {
+		b.deadline = d.engine.clock.Now().Add(d.wait)
+		b.timer.Reset(d.wait)
+		d.mu.Unlock()
+		d.deliver(events, index)
+		return
+	}
+
+	delete(d.bursts, key)
+	d.mu.Unlock()
+	d.deliver(events, index)
+	d.engine.endDispatch()
+}
+
+// flush delivers all pending bursts right away.
+func (d *deferrer) flush() {
+	d.mu.Lock()
+	bursts := d.bursts
+	d.bursts = map[string]*pendingBurst{}
+	d.mu.Unlock()
+
+	for _, b := range bursts {
+		b.timer.Stop()
+		go func() {
+			defer d.engine.endDispatch()
+			d.deliver(b.events, b.index)
+		}()
+	}
+}
+
+// deliver runs the handler for the last event of a burst.
+func (d *deferrer) deliver(events []Event, index int) {
+	if len(events) == 0 {
+		return
+	}
+
+	event := events[len(events)-1]
+	if d.burst {
+		event.Data = Burst(events)
+	}
+	if err := d.sub.callNow(event, index); err != nil && d.engine.errorHandler != nil {
+		d.engine.errorHandler(event, err)
+	}
+}
@@ -0,0 +1,130 @@
+package beacon_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestDebounce(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+
+	var delivered []any
+	engine.Subscribe("search.typed", func(e beacon.Event) error {
+		delivered = append(delivered, e.Data)
+		return nil
+	}, beacon.Debounce(30*time.Millisecond))
+
+	for _, query := range []string{"b", "be", "bea"} {
+		if err := engine.Submit("search.typed", query); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(5 * time.Millisecond)
+	}
+
+	// The last event was submitted 5ms ago
+	clock.Advance(24 * time.Millisecond)
+	if len(delivered) != 0 {
+		t.Fatalf("expected delivery to wait for the quiet period, got %v", delivered)
+	}
+	clock.Advance(time.Millisecond)
+	if len(delivered) != 1 || delivered[0] != "bea" {
+		t.Fatalf("expected last event to be delivered, got %v", delivered)
+	}
+
+	clock.Advance(time.Hour)
+	if len(delivered) != 1 {
+		t.Errorf("expected a single delivery, got %v", delivered)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+
+	var delivered []any
+	engine.Subscribe("cursor.moved", func(e beacon.Event) error {
+		delivered = append(delivered, e.Data)
+		return nil
+	}, beacon.Throttle(40*time.Millisecond))
+
+	for i := range 5 {
+		if err := engine.Submit("cursor.moved", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(delivered) != 1 || delivered[0] != 0 {
+		t.Fatalf("expected leading event to be delivered right away, got %v", delivered)
+	}
+
+	clock.Advance(39 * time.Millisecond)
+	if len(delivered) != 1 {
+		t.Fatalf("expected trailing event to wait for the interval, got %v", delivered)
+	}
+	clock.Advance(time.Millisecond)
+	if len(delivered) != 2 || delivered[1] != 4 {
+		t.Fatalf("expected trailing event to be the last one, got %v", delivered)
+	}
+
+	// An interval without events ends the throttling
+	clock.Advance(40 * time.Millisecond)
+	if err := engine.Submit("cursor.moved", 5); err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 3 || delivered[2] != 5 {
+		t.Errorf("expected event after a quiet interval to be delivered right away, got %v", delivered)
+	}
+}
+
+func TestCoalesceByBurst(t *testing.T) {
+	engine := beacon.New()
+
+	var mu sync.Mutex
+	bursts := map[string]beacon.Burst{}
+	engine.Subscribe("order.updated", func(e beacon.Event) error {
+		burst := e.Data.(beacon.Burst)
+		mu.Lock()
+		defer mu.Unlock()
+		bursts[burst[0].Data.(OrderUpdated).OrderID] = burst
+		return nil
+	},
+		beacon.Debounce(time.Hour),
+		beacon.CoalesceBy(func(e beacon.Event) string { return e.Data.(OrderUpdated).OrderID }),
+		beacon.DeliverBurst(),
+	)
+
+	for seq := range 3 {
+		for _, order := range []string{"a", "b"} {
+			if err := engine.Submit("order.updated", OrderUpdated{OrderID: order, Seq: seq}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Drain delivers pending bursts right away
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := engine.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, order := range []string{"a", "b"} {
+		burst := bursts[order]
+		if len(burst) != 3 {
+			t.Errorf("%s: expected a burst of 3 events, got %d", order, len(burst))
+			continue
+		}
+		for i, e := range burst {
+			if e.Data.(OrderUpdated).Seq != i {
+				t.Errorf("%s: events out of order", order)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package beacon
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DedupStore remembers the IDs of events that have already been processed.
+type DedupStore interface {
+	// Add records an event ID and reports whether it was already present.
+	Add(id string) bool
+	// Remove forgets an event ID, allowing the event to be processed again.
+	Remove(id string)
+}
+
+// LRUDedupStore is an in-memory DedupStore bounded in size with an optional TTL.
+type LRUDedupStore struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	clock Clock
+	order *list.List
+	items map[string]*list.Element
+}
+
+type dedupEntry struct {
+	id      string
+	expires time.Time
+}
+
+// NewLRUDedupStore creates a DedupStore that keeps at most size IDs, each for at most ttl.
+// A ttl of zero keeps IDs until they are evicted.
+func NewLRUDedupStore(size int, ttl time.Duration) *LRUDedupStore {
+	return &LRUDedupStore{
+		size:  size,
+		ttl:   ttl,
+		clock: RealClock{},
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Add records an event ID and reports whether it was already present.
+func (s *LRUDedupStore) Add(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if elem, ok := s.items[id]; ok {
+		entry := elem.Value.(*dedupEntry)
+		expired := s.ttl > 0 && now.After(entry.expires)
+		entry.expires = now.Add(s.ttl)
+		s.order.MoveToFront(elem)
+		return !expired
+	}
+
+	s.items[id] = s.order.PushFront(&dedupEntry{id: id, expires: now.Add(s.ttl)})
+	for s.size > 0 && s.order.Len() > s.size {
+		s.removeElement(s.order.Back())
+	}
+	return false
+}
+
+// Remove forgets an event ID, allowing the event to be processed again.
+func (s *LRUDedupStore) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[id]; ok {
+		s.removeElement(elem)
+	}
+}
+
+// useClock makes the store expire IDs by the clock of the engine using it.
+func (s *LRUDedupStore) useClock(clock Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// Len returns the number of remembered IDs, including expired ones not yet evicted.
+func (s *LRUDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *LRUDedupStore) removeElement(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*dedupEntry).id)
+}
@@ -0,0 +1,52 @@
+package beacon_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestLRUDedupStore(t *testing.T) {
+	store := beacon.NewLRUDedupStore(2, 0)
+
+	if store.Add("a") {
+		t.Error("unseen ID reported as seen")
+	}
+	if !store.Add("a") {
+		t.Error("seen ID reported as unseen")
+	}
+
+	store.Add("b")
+	store.Add("c") // evicts "a"
+	if store.Len() != 2 {
+		t.Errorf("expected 2 IDs, got %d", store.Len())
+	}
+	if store.Add("a") {
+		t.Error("evicted ID reported as seen")
+	}
+
+	store.Remove("c")
+	if store.Add("c") {
+		t.Error("removed ID reported as seen")
+	}
+}
+
+func TestLRUDedupStoreTTL(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := beacon.NewLRUDedupStore(8, time.Minute)
+	// The event service hands the clock of the engine to the store
+	beacon.NewMemoryTransport(beacon.New(beacon.WithClock(clock)), beacon.WithDeduplication(store))
+
+	store.Add("a")
+	clock.Advance(time.Minute)
+	if !store.Add("a") {
+		t.Error("ID reported as unseen before it expired")
+	}
+
+	clock.Advance(time.Minute + time.Millisecond)
+	if store.Add("a") {
+		t.Error("expired ID reported as seen")
+	}
+}
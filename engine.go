@@ -2,37 +2,74 @@ package beacon
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	protoc "github.com/YONEDASH/beacon/internal/protoc"
 	"google.golang.org/grpc"
 )
 
 // Event represents a data structure that is passed to event handlers.
 type Event struct {
+	ID        string
+	Name      string
 	Context   context.Context
 	Timestamp time.Time
 	Data      any
-	canceled  *bool
+	canceled  *atomic.Bool
 }
 
 // Cancel stops propagation of the event to further handlers.
 func (e Event) Cancel() {
 	if e.canceled != nil {
-		*e.canceled = true
+		e.canceled.Store(true)
 	}
 }
 
-// newEvent creates an Event instance with the given context and data.
-func newEvent(ctx context.Context, v any) Event {
+// Result describes how the handlers of an engine processed an event.
+type Result struct {
+	// Canceled is true if a handler stopped propagation with Event.Cancel.
+	Canceled bool
+	// Paused is true if the event was held back because its name is paused.
+	Paused bool
+	// HandlersRun is the number of handlers that were invoked.
+	HandlersRun int
+	// Errors holds the errors returned by handlers.
+	Errors []*HandlerError
+}
+
+// SubmitResult describes how a submitted event was processed.
+type SubmitResult struct {
+	Local Result
+	// Remote combines the results reported by the remote servers, or is nil if no remote received the event.
+	Remote *Result
+	// Remotes holds the outcome for each remote server the event was sent to.
+	Remotes []RemoteResult
+}
+
+// newEvent creates an Event instance with the given context, name and data.
+func (s *Engine) newEvent(ctx context.Context, name string, v any) Event {
 	return Event{
+		ID:        newEventID(),
+		Name:      name,
 		Context:   ctx,
-		Timestamp: time.Now(),
+		Timestamp: s.clock.Now(),
 		Data:      v,
 	}
 }
 
+// newEventID returns a random identifier used to recognize redelivered events.
+func newEventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
 // Handler is a function that processes an event.
 type Handler func(Event) error
 
@@ -40,46 +77,335 @@ type Handler func(Event) error
 type Option func(*Engine)
 
 // WithRemote configures the Show instance to send events to a remote server using gRPC.
+// It may be used multiple times to send events to several servers.
 func WithRemote(conn *grpc.ClientConn) Option {
+	return WithRemoteRoute(conn)
+}
+
+// WithRemoteRoute configures the Engine to send events whose names match one of the
+// patterns to a remote server using gRPC. Patterns use the syntax of path.Match, e.g. "billing.*".
+// Without patterns, all events are sent to the server.
+func WithRemoteRoute(conn *grpc.ClientConn, patterns ...string) Option {
+	return WithTransport(NewGRPCTransport(conn), patterns...)
+}
+
+// WithTransport configures the Engine to deliver events whose names match one of the
+// patterns through a transport. Without patterns, all events are delivered.
+func WithTransport(transport Transport, patterns ...string) Option {
 	return func(ls *Engine) {
-		ls.grpcClient = protoc.NewEventServiceClient(conn)
+		ls.remotes = append(ls.remotes, remote{
+			target:    transportTarget(transport, len(ls.remotes)),
+			transport: transport,
+			patterns:  patterns,
+		})
+	}
+}
+
+// WithDeliveryPolicy configures how failures of remote servers affect a submission.
+func WithDeliveryPolicy(policy DeliveryPolicy) Option {
+	return func(ls *Engine) {
+		ls.deliveryPolicy = policy
+	}
+}
+
+// WithPropagator configures the values of the submit context that are sent to the remote server.
+func WithPropagator(propagator Propagator) Option {
+	return func(ls *Engine) {
+		ls.propagator = propagator
 	}
 }
 
 // New creates an instance of Show to manage event handlers.
 func New(opts ...Option) *Engine {
 	engine := &Engine{
-		handlers: make(map[string][]Handler),
+		handlers:  make(map[string][]*subscription),
+		clock:     RealClock{},
+		holdLimit: defaultHoldLimit,
 	}
 
 	for _, opt := range opts {
 		opt(engine)
 	}
+	for _, l := range engine.rateLimits {
+		l.clock = engine.clock
+	}
+	for _, r := range engine.remotes {
+		if transport, ok := r.transport.(clockUser); ok {
+			transport.useClock(engine.clock)
+		}
+	}
+	if engine.scheduleStore != nil {
+		engine.restoreScheduled()
+	}
 
 	return engine
 }
 
 // Engine manages event handlers that are triggered in a context-aware manner.
 type Engine struct {
-	handlers map[string][]Handler
+	mu        sync.RWMutex
+	handlers  map[string][]*subscription
+	patterns  []*subscription
+	observers []*subscription
+	groups    map[groupKey]*consumerGroup
+	balancers map[string]Balancer
+	batchers  []*batcher
+	nextID    uint64
+
+	remotes        []remote
+	deliveryPolicy DeliveryPolicy
+	propagator     Propagator
+	clock          Clock
+
+	drainMu    sync.Mutex
+	draining   bool
+	inflight   int
+	drained    chan struct{}
+	drainHooks []func()
+
+	pauseMu   sync.Mutex
+	paused    map[string]bool
+	held      []Event
+	holdLimit int
+
+	statsMu     sync.Mutex
+	stats       map[string]*EventStats
+	deadLetters DeadLetterStore
 
-	grpcClient protoc.EventServiceClient
+	workers       int
+	queueSize     int
+	partitionKeys []partitionKey
+	parallel      []parallelHandlers
+	rateLimits    []*rateLimiter
+	errorHandler  func(Event, error)
+	asyncMu       sync.RWMutex
+	queues        []chan Event
+	asyncStopped  bool
+	asyncDone     sync.WaitGroup
+
+	scheduleStore   ScheduleStore
+	scheduleMu      sync.Mutex
+	scheduleQueue   scheduleQueue
+	scheduled       map[string]*scheduledItem
+	scheduleTimer   Timer
+	scheduleStarted bool
+	scheduleStopped bool
+	cronJobs        []*cronJob
+}
+
+// subscription is a handler registered for an event name or pattern.
+type subscription struct {
+	id         uint64
+	pattern    string
+	handler    Handler
+	sequential bool
+	name       string
+	timeout    time.Duration
+	breaker    *circuitBreaker
+	deferral   *deferral
+	deferrer   *deferrer
+}
+
+// SubscribeOption is a functional option for configuring a subscription.
+type SubscribeOption func(*subscription)
+
+// Sequential keeps a handler from running concurrently with other handlers of an event
+// configured with WithParallelHandlers. It runs after the handlers subscribed before it
+// have returned, and the handlers subscribed after it only run if it did not fail or
+// cancel the event. Handlers relying on Cancel should be sequential.
+func Sequential() SubscribeOption {
+	return func(sub *subscription) {
+		sub.sequential = true
+	}
 }
 
-// hasRemote returns true if the remote server is enabled.
-func (s *Engine) hasRemote() bool {
-	return s.grpcClient != nil
+// call runs the handler of a subscription for an event, enforcing its debouncing, circuit
+// breaker and timeout. The index is the position of the subscription in the subscription order.
+func (sub *subscription) call(event Event, index int) error {
+	if sub.deferrer != nil {
+		return sub.deferrer.add(event, index)
+	}
+	return sub.callNow(event, index)
+}
+
+// callNow runs the handler of a subscription for an event right away.
+func (sub *subscription) callNow(event Event, index int) error {
+	if sub.breaker == nil {
+		return sub.invoke(event, index)
+	}
+
+	if !sub.breaker.allow(event.Name, sub.name) {
+		if sub.breaker.settings.Skip {
+			return nil
+		}
+		return ErrCircuitOpen
+	}
+	err := sub.invoke(event, index)
+	sub.breaker.record(event.Name, sub.name, err)
+	return err
 }
 
 // Size returns the number of registered handlers for an event name.
 func (s *Engine) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return len(s.handlers)
 }
 
+// hasHandlers returns true if at least one handler is registered for an event name.
+func (s *Engine) hasHandlers(eventName string) bool {
+	return len(s.subscriptionsFor(eventName)) > 0
+}
+
+// subscriptionsFor returns the handlers of an event name followed by the matching pattern handlers.
+func (s *Engine) subscriptionsFor(eventName string) []*subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subs := append([]*subscription(nil), s.handlers[eventName]...)
+	for _, sub := range s.patterns {
+		if matchEventName(sub.pattern, eventName) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
 // Subscribe adds a handler function for a specific event name.
 // Event names must be non-empty strings.
-func (s *Engine) Subscribe(eventName string, handler Handler) {
-	s.handlers[eventName] = append(s.handlers[eventName], handler)
+func (s *Engine) Subscribe(eventName string, handler Handler, opts ...SubscribeOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	sub := &subscription{id: s.nextID, pattern: eventName, handler: handler}
+	for _, opt := range opts {
+		opt(sub)
+	}
+	if sub.breaker != nil {
+		sub.breaker.clock = s.clock
+		sub.breaker.notify = s.notifyCircuitChange
+	}
+	if sub.deferral != nil && sub.deferral.wait > 0 {
+		sub.deferrer = newDeferrer(s, sub)
+	}
+	s.handlers[eventName] = append(s.handlers[eventName], sub)
+}
+
+// subscribePattern adds a handler for all events whose names match a pattern
+// and returns a function removing it again.
+func (s *Engine) subscribePattern(pattern string, handler Handler) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := s.nextID
+	s.patterns = append(s.patterns, &subscription{id: id, pattern: pattern, handler: handler})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.patterns = removeSubscription(s.patterns, id)
+	}
+}
+
+// observe adds a function that sees every fired event before its handlers run, without
+// being counted as a handler. It returns a function removing the observer again.
+func (s *Engine) observe(fn func(Event)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := s.nextID
+	s.observers = append(s.observers, &subscription{id: id, handler: func(e Event) error {
+		fn(e)
+		return nil
+	}})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.observers = removeSubscription(s.observers, id)
+	}
+}
+
+// removeSubscription returns a copy of subs without the subscription with the given ID.
+func removeSubscription(subs []*subscription, id uint64) []*subscription {
+	for i, sub := range subs {
+		if sub.id == id {
+			return append(subs[:i:i], subs[i+1:]...)
+		}
+	}
+	return subs
+}
+
+// SubscribeRemote adds a handler for events matching a pattern that are published on the
+// remote side of the engine's transports. It returns a function removing the handler again,
+// or ErrSubscribeUnsupported if none of the transports support subscriptions.
+func (s *Engine) SubscribeRemote(pattern string, handler Handler) (func(), error) {
+	return s.subscribeRemotes(func(t Transport) (func(), error) {
+		if transport, ok := t.(SubscribingTransport); ok {
+			return transport.Subscribe(pattern, handler)
+		}
+		return nil, nil
+	})
+}
+
+// SubscribeRemoteGroup adds a handler to a consumer group on the remote side of the engine's
+// transports, see SubscribeGroup. It returns a function leaving the group again, or
+// ErrSubscribeUnsupported if none of the transports support consumer groups.
+func (s *Engine) SubscribeRemoteGroup(group, pattern string, handler Handler) (func(), error) {
+	return s.subscribeRemotes(func(t Transport) (func(), error) {
+		if transport, ok := t.(GroupSubscribingTransport); ok {
+			return transport.SubscribeGroup(group, pattern, handler)
+		}
+		return nil, nil
+	})
+}
+
+// subscribeRemotes subscribes to every transport supporting it and returns a function
+// removing all subscriptions again. Transports not supporting the subscription return nil.
+func (s *Engine) subscribeRemotes(subscribe func(Transport) (func(), error)) (func(), error) {
+	var unsubscribes []func()
+	unsubscribe := func() {
+		for _, fn := range unsubscribes {
+			fn()
+		}
+	}
+
+	for _, r := range s.remotes {
+		fn, err := subscribe(r.transport)
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+		if fn != nil {
+			unsubscribes = append(unsubscribes, fn)
+		}
+	}
+
+	if len(unsubscribes) == 0 {
+		return nil, ErrSubscribeUnsupported
+	}
+	return unsubscribe, nil
+}
+
+// Close stops firing scheduled events and cron schedules, waits for the events queued by
+// SubmitAsync to be dispatched, delivers pending batches and releases the transports of
+// the engine. Events queued or scheduled afterwards fail with ErrClosed.
+func (s *Engine) Close() error {
+	s.stopSchedule()
+	s.stopCron()
+	s.stopWorkers()
+	s.flushBatches()
+
+	var errs []error
+	for _, r := range s.remotes {
+		if err := r.transport.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // Submit invokes the handler functions when an event is submitted.
@@ -89,54 +415,203 @@ func (s *Engine) Submit(eventName string, data any) error {
 
 // SubmitWithContext invokes the handler functions when an event is submitted with a context.
 func (s *Engine) SubmitWithContext(ctx context.Context, eventName string, data any) error {
+	_, err := s.SubmitWithResult(ctx, eventName, data)
+	return err
+}
+
+// SubmitWithResult invokes the handler functions like SubmitWithContext and reports how
+// the event was processed locally and, if enabled, by the remote servers.
+func (s *Engine) SubmitWithResult(ctx context.Context, eventName string, data any) (SubmitResult, error) {
+	var submitResult SubmitResult
 	if eventName == "" {
-		return errors.New("event name is required")
+		return submitResult, ErrEventNameRequired
+	}
+	if s.Draining() {
+		return submitResult, ErrDraining
+	}
+	if dropped, err := applyRateLimits(ctx, s.rateLimits, eventName); dropped || err != nil {
+		return submitResult, err
 	}
 
-	event := newEvent(ctx, data)
+	event := s.newEvent(ctx, eventName, data)
 
-	// If remote is enabled, send the event to the remote server
-	if s.hasRemote() {
-		if err := grpcPostEvent(ctx, s.grpcClient, eventName, event); err != nil {
-			return err
+	// If remote is enabled, send the event to the remote servers
+	if len(s.remotes) > 0 {
+		if err := s.postRemotes(ctx, event, &submitResult); err != nil {
+			return submitResult, err
 		}
 	}
 
-	result := make(chan error)
+	type fired struct {
+		result Result
+		err    error
+	}
+	done := make(chan fired, 1)
 	go func() {
-		result <- s.fireEvent(eventName, event)
+		result, err := s.fireEvent(eventName, event)
+		done <- fired{result, err}
 	}()
 
 	select {
 	case <-event.Context.Done():
-		return event.Context.Err() // Respect cancelation or timeout
-	case err := <-result:
-		return err
+		return submitResult, event.Context.Err() // Respect cancelation or timeout
+	case f := <-done:
+		submitResult.Local = f.result
+		return submitResult, f.err
 	}
 }
 
-// fireEvent executes all registered handlers for a specific event.
-func (s *Engine) fireEvent(eventName string, event Event) error {
-	handlers, ok := s.handlers[eventName]
-	if !ok {
+// Drain stops the engine from accepting new events and waits until the handlers of
+// events already being processed have returned, or until ctx is done.
+func (s *Engine) Drain(ctx context.Context) error {
+	s.drainMu.Lock()
+	var hooks []func()
+	if !s.draining {
+		s.draining = true
+		s.drained = make(chan struct{})
+		if s.inflight == 0 {
+			close(s.drained)
+		}
+		hooks = s.drainHooks
+	}
+	drained := s.drained
+	s.drainMu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	select {
+	case <-drained:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	event.canceled = new(bool)
+// Draining returns true once Drain was called.
+func (s *Engine) Draining() bool {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	return s.draining
+}
 
-	for _, handle := range handlers {
-		if err := handle(event); err != nil {
-			return err
+// onDrain registers a function called once the engine starts draining.
+// It is called right away if the engine is already draining.
+func (s *Engine) onDrain(hook func()) {
+	s.drainMu.Lock()
+	draining := s.draining
+	if !draining {
+		s.drainHooks = append(s.drainHooks, hook)
+	}
+	s.drainMu.Unlock()
+
+	if draining {
+		hook()
+	}
+}
+
+// beginDispatch registers an event being processed, unless the engine is draining.
+func (s *Engine) beginDispatch() error {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	if s.draining {
+		return ErrDraining
+	}
+	s.inflight++
+	return nil
+}
+
+// endDispatch unregisters an event being processed.
+func (s *Engine) endDispatch() {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+
+	s.inflight--
+	if s.draining && s.inflight == 0 {
+		close(s.drained)
+	}
+}
+
+// fireEvent executes all registered handlers for a specific event, unless its name is paused.
+func (s *Engine) fireEvent(eventName string, event Event) (Result, error) {
+	if err := s.beginDispatch(); err != nil {
+		return Result{}, err
+	}
+	defer s.endDispatch()
+
+	return s.dispatchEvent(eventName, event)
+}
+
+// dispatchEvent executes the handlers of an event registered with beginDispatch.
+func (s *Engine) dispatchEvent(eventName string, event Event) (Result, error) {
+	event.Name = eventName
+	if held, err := s.hold(event); held || err != nil {
+		return Result{Paused: held}, err
+	}
+
+	start := s.clock.Now()
+	result, err := s.runHandlers(event)
+	s.recordStats(eventName, result, s.clock.Now().Sub(start))
+	if len(result.Errors) > 0 {
+		s.recordDeadLetter(event, err)
+	}
+	return result, err
+}
+
+// runHandlers calls the observers and handlers of an event.
+func (s *Engine) runHandlers(event Event) (Result, error) {
+	var result Result
+	eventName := event.Name
+
+	s.mu.RLock()
+	observers := s.observers
+	s.mu.RUnlock()
+	for _, observer := range observers {
+		observer.handler(event)
+	}
+
+	subs := s.subscriptionsFor(eventName)
+	if len(subs) == 0 {
+		return result, nil
+	}
+
+	event.canceled = new(atomic.Bool)
+	limit := s.parallelism(eventName)
+
+	for i := 0; i < len(subs); {
+		// Run the next handler alone, or the following handlers that are not sequential together
+		j := i + 1
+		if limit > 1 && !subs[i].sequential {
+			for j < len(subs) && !subs[j].sequential {
+				j++
+			}
 		}
-		if *event.canceled {
-			return nil
+
+		result.HandlersRun += j - i
+		if j-i == 1 {
+			if err := subs[i].call(event, i); err != nil {
+				result.Errors = append(result.Errors, &HandlerError{EventName: eventName, Index: i, Err: err})
+			}
+		} else {
+			result.Errors = append(result.Errors, runParallel(event, subs[i:j], i, limit)...)
+		}
+		if len(result.Errors) > 0 {
+			return result, result.Errors[0].Err
+		}
+
+		if event.canceled.Load() {
+			result.Canceled = true
+			return result, nil
 		}
 		select {
 		case <-event.Context.Done():
-			return event.Context.Err()
+			return result, event.Context.Err()
 		default:
 			// Continue to the next handler if the context is not done
 		}
+		i = j
 	}
-	return nil
+	return result, nil
 }
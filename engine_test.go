@@ -130,3 +130,29 @@ func TestContextTimeout(t *testing.T) {
 		t.Error("context error not received")
 	}
 }
+
+func TestSubmitWithResult(t *testing.T) {
+	engine := beacon.New()
+	engine.Subscribe("test", func(e beacon.Event) error {
+		return nil
+	})
+	engine.Subscribe("test", func(e beacon.Event) error {
+		e.Cancel()
+		return nil
+	})
+	engine.Subscribe("test", func(e beacon.Event) error {
+		return nil
+	})
+
+	result, err := engine.SubmitWithResult(context.Background(), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Local.Canceled || result.Local.HandlersRun != 2 {
+		t.Errorf("unexpected result: %+v", result.Local)
+	}
+	if result.Remote != nil {
+		t.Error("remote result reported without remote")
+	}
+}
@@ -0,0 +1,229 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+var (
+	// ErrEventNameRequired is returned when an event is submitted without a name.
+	ErrEventNameRequired = errors.New("event name is required")
+	// ErrInvalidPayload is returned when the event data cannot be decoded.
+	ErrInvalidPayload = errors.New("invalid event payload")
+	// ErrUnknownEvent is returned by servers rejecting events without subscribers.
+	ErrUnknownEvent = errors.New("unknown event")
+	// ErrEventNotAllowed is returned by servers rejecting events by name.
+	ErrEventNotAllowed = errors.New("event not allowed")
+	// ErrDraining is returned when an event is submitted to an engine that is draining.
+	ErrDraining = errors.New("engine is draining")
+	// ErrClosed is returned when an event is queued or scheduled on an engine that is closed.
+	ErrClosed = errors.New("engine is closed")
+	// ErrUnauthenticated is returned by servers that cannot authenticate the client.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied is returned by servers when the client may not submit an event.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrSubscribeUnsupported is returned when no transport supports remote subscriptions.
+	ErrSubscribeUnsupported = errors.New("remote subscriptions are not supported by the transports")
+)
+
+// HandlerError reports that a handler failed while processing an event.
+type HandlerError struct {
+	EventName string
+	// Index is the position of the failed handler in the subscription order.
+	Index int
+	Err   error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler for event %q failed: %v", e.EventName, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// HandlerTimeoutError reports that a handler did not return within its timeout.
+type HandlerTimeoutError struct {
+	EventName string
+	// Index is the position of the handler in the subscription order.
+	Index int
+	// Handler is the name given to the handler with HandlerName, if any.
+	Handler string
+	Timeout time.Duration
+}
+
+func (e *HandlerTimeoutError) Error() string {
+	if e.Handler != "" {
+		return fmt.Sprintf("handler %q for event %q timed out after %v", e.Handler, e.EventName, e.Timeout)
+	}
+	return fmt.Sprintf("handler #%d for event %q timed out after %v", e.Index, e.EventName, e.Timeout)
+}
+
+// BatchError reports that a batch handler kept failing for a batch of events.
+type BatchError struct {
+	EventName string
+	// Size is the number of events in the batch.
+	Size int
+	// Attempts is how often the handler was called for the batch.
+	Attempts int
+	Err      error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch handler for event %q failed for %d events after %d attempts: %v", e.EventName, e.Size, e.Attempts, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// errorDomain identifies beacon errors in gRPC error details.
+const errorDomain = "beacon"
+
+// Reasons identifying beacon errors, attached to gRPC statuses as errdetails.ErrorInfo.
+const (
+	reasonEventNameRequired = "EVENT_NAME_REQUIRED"
+	reasonInvalidPayload    = "INVALID_PAYLOAD"
+	reasonUnknownEvent      = "UNKNOWN_EVENT"
+	reasonEventNotAllowed   = "EVENT_NOT_ALLOWED"
+	reasonHandlerFailed     = "HANDLER_FAILED"
+	reasonDraining          = "DRAINING"
+	reasonUnauthenticated   = "UNAUTHENTICATED"
+	reasonPermissionDenied  = "PERMISSION_DENIED"
+	reasonRateLimited       = "RATE_LIMITED"
+	reasonHoldLimit         = "HOLD_LIMIT"
+)
+
+// classifyError returns the gRPC code of an error returned by the engine and,
+// for beacon errors, the reason and metadata needed to restore it on the client.
+// Handler failures are classified first, whatever error the handler wrapped.
+func classifyError(err error) (codes.Code, string, map[string]string) {
+	var handlerErr *HandlerError
+	switch {
+	case errors.As(err, &handlerErr):
+		return codes.Internal, reasonHandlerFailed, map[string]string{
+			"event_name": handlerErr.EventName,
+			"index":      strconv.Itoa(handlerErr.Index),
+			"error":      handlerErr.Err.Error(),
+		}
+	case errors.Is(err, ErrEventNameRequired):
+		return codes.InvalidArgument, reasonEventNameRequired, nil
+	case errors.Is(err, ErrInvalidPayload):
+		return codes.InvalidArgument, reasonInvalidPayload, nil
+	case errors.Is(err, ErrUnknownEvent):
+		return codes.NotFound, reasonUnknownEvent, nil
+	case errors.Is(err, ErrEventNotAllowed):
+		return codes.InvalidArgument, reasonEventNotAllowed, nil
+	case errors.Is(err, ErrDraining):
+		return codes.Unavailable, reasonDraining, nil
+	case errors.Is(err, ErrUnauthenticated):
+		return codes.Unauthenticated, reasonUnauthenticated, nil
+	case errors.Is(err, ErrPermissionDenied):
+		return codes.PermissionDenied, reasonPermissionDenied, nil
+	case errors.Is(err, ErrRateLimited):
+		return codes.ResourceExhausted, reasonRateLimited, nil
+	case errors.Is(err, ErrHoldLimit):
+		return codes.ResourceExhausted, reasonHoldLimit, nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, "", nil
+	case errors.Is(err, context.Canceled):
+		return codes.Aborted, "", nil
+	}
+	return codes.Internal, "", nil
+}
+
+// restoreError converts a classified error back into a beacon error.
+// It returns nil if neither the reason nor the code identify a beacon error.
+func restoreError(code codes.Code, reason, message string, metadata map[string]string) error {
+	switch reason {
+	case reasonEventNameRequired:
+		return ErrEventNameRequired
+	case reasonInvalidPayload:
+		return fmt.Errorf("%w: %s", ErrInvalidPayload, message)
+	case reasonUnknownEvent:
+		return ErrUnknownEvent
+	case reasonEventNotAllowed:
+		return ErrEventNotAllowed
+	case reasonDraining:
+		return ErrDraining
+	case reasonUnauthenticated:
+		return ErrUnauthenticated
+	case reasonPermissionDenied:
+		return ErrPermissionDenied
+	case reasonRateLimited:
+		return ErrRateLimited
+	case reasonHoldLimit:
+		return ErrHoldLimit
+	case reasonHandlerFailed:
+		index, _ := strconv.Atoi(metadata["index"])
+		return &HandlerError{
+			EventName: metadata["event_name"],
+			Index:     index,
+			Err:       errors.New(metadata["error"]),
+		}
+	}
+
+	switch code {
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	case codes.Aborted, codes.Canceled:
+		return context.Canceled
+	}
+	return nil
+}
+
+// toStatus converts an error returned by the engine into a gRPC status error with the
+// additional details. Beacon errors also carry error info.
+func toStatus(err error, details ...protoadapt.MessageV1) error {
+	if err == nil {
+		return nil
+	}
+
+	code, reason, metadata := classifyError(err)
+	st := status.New(code, err.Error())
+	if reason != "" {
+		details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{
+			Reason:   reason,
+			Domain:   errorDomain,
+			Metadata: metadata,
+		}}, details...)
+	}
+	if len(details) == 0 {
+		return st.Err()
+	}
+
+	withDetails, detailErr := st.WithDetails(details...)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// fromStatus converts a gRPC status error returned by a server into a beacon error.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return err
+	}
+
+	reason, metadata := "", map[string]string(nil)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			reason, metadata = info.Reason, info.Metadata
+			break
+		}
+	}
+
+	if restored := restoreError(st.Code(), reason, st.Message(), metadata); restored != nil {
+		return restored
+	}
+	return err
+}
@@ -0,0 +1,144 @@
+package beacon
+
+import (
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Balancer picks the member of a consumer group that handles an event.
+type Balancer interface {
+	// Pick returns the index of the member handling the event. Members are identified
+	// by IDs that stay the same while they are part of the group.
+	Pick(e Event, members []string) int
+}
+
+// BalancerFunc adapts a function to the Balancer interface.
+type BalancerFunc func(e Event, members []string) int
+
+func (f BalancerFunc) Pick(e Event, members []string) int {
+	return f(e, members)
+}
+
+// RoundRobin returns a Balancer handing events to the members of a group in turn.
+func RoundRobin() Balancer {
+	var next atomic.Uint64
+	return BalancerFunc(func(e Event, members []string) int {
+		return int((next.Add(1) - 1) % uint64(len(members)))
+	})
+}
+
+// ConsistentHash returns a Balancer handing all events with the same partition key to
+// the same member. When members join or leave, only the keys of the affected members
+// move to other members.
+func ConsistentHash(key func(e Event) string) Balancer {
+	return BalancerFunc(func(e Event, members []string) int {
+		k := key(e)
+		best, bestWeight := 0, uint64(0)
+		for i, member := range members {
+			// Rendezvous hashing: the member with the highest weight for the key wins
+			h := fnv.New64a()
+			h.Write([]byte(k))
+			h.Write([]byte{0})
+			h.Write([]byte(member))
+			if weight := h.Sum64(); i == 0 || weight > bestWeight {
+				best, bestWeight = i, weight
+			}
+		}
+		return best
+	})
+}
+
+// WithGroupBalancer configures how the events of a consumer group are distributed
+// among its members. Groups use RoundRobin unless configured otherwise.
+func WithGroupBalancer(group string, balancer Balancer) Option {
+	return func(engine *Engine) {
+		if engine.balancers == nil {
+			engine.balancers = make(map[string]Balancer)
+		}
+		engine.balancers[group] = balancer
+	}
+}
+
+// consumerGroup shares the events matching a pattern among its members.
+type consumerGroup struct {
+	subID    uint64
+	balancer Balancer
+
+	mu      sync.RWMutex
+	ids     []string
+	members []Handler
+}
+
+func (g *consumerGroup) handle(e Event) error {
+	g.mu.RLock()
+	if len(g.members) == 0 {
+		g.mu.RUnlock()
+		return nil
+	}
+	handler := g.members[g.balancer.Pick(e, g.ids)]
+	g.mu.RUnlock()
+
+	return handler(e)
+}
+
+// groupKey identifies a consumer group by its name and pattern.
+type groupKey struct {
+	name    string
+	pattern string
+}
+
+// SubscribeGroup adds a handler to a consumer group for events whose names match a pattern.
+// Each event is handled by only one member of the group, chosen by the balancer of the
+// group, while other groups and handlers receive their own copy. Members joining or
+// leaving the group rebalance its events. It returns a function removing the handler again.
+func (s *Engine) SubscribeGroup(group, pattern string, handler Handler) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := groupKey{name: group, pattern: pattern}
+	g, ok := s.groups[key]
+	if !ok {
+		balancer := s.balancers[group]
+		if balancer == nil {
+			balancer = RoundRobin()
+		}
+		s.nextID++
+		g = &consumerGroup{subID: s.nextID, balancer: balancer}
+		s.patterns = append(s.patterns, &subscription{id: g.subID, pattern: pattern, handler: g.handle})
+
+		if s.groups == nil {
+			s.groups = make(map[groupKey]*consumerGroup)
+		}
+		s.groups[key] = g
+	}
+
+	s.nextID++
+	memberID := strconv.FormatUint(s.nextID, 10)
+
+	g.mu.Lock()
+	g.ids = append(g.ids, memberID)
+	g.members = append(g.members, handler)
+	g.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		for i, id := range g.ids {
+			if id == memberID {
+				g.ids = append(g.ids[:i:i], g.ids[i+1:]...)
+				g.members = append(g.members[:i:i], g.members[i+1:]...)
+				break
+			}
+		}
+
+		if len(g.members) == 0 && s.groups[key] == g {
+			delete(s.groups, key)
+			s.patterns = removeSubscription(s.patterns, g.subID)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package beacon_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+)
+
+func TestSubscribeGroup(t *testing.T) {
+	engine := beacon.New()
+
+	counts := make([]int, 3)
+	for i := range 2 {
+		engine.SubscribeGroup("mailer", "order.*", func(e beacon.Event) error {
+			counts[i]++
+			return nil
+		})
+	}
+	engine.SubscribeGroup("audit", "order.*", func(e beacon.Event) error {
+		counts[2]++
+		return nil
+	})
+
+	for range 4 {
+		if err := engine.Submit("order.placed", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if counts[0] != 2 || counts[1] != 2 || counts[2] != 4 {
+		t.Errorf("unexpected distribution: %v", counts)
+	}
+}
+
+func TestConsistentHashGroup(t *testing.T) {
+	engine := beacon.New(beacon.WithGroupBalancer("billing", beacon.ConsistentHash(func(e beacon.Event) string {
+		return e.Data.(string)
+	})))
+
+	owners := map[string]int{}
+	member := func(i int) beacon.Handler {
+		return func(e beacon.Event) error {
+			customer := e.Data.(string)
+			if owner, ok := owners[customer]; ok && owner != i {
+				t.Errorf("customer %s moved from member %d to %d", customer, owner, i)
+			}
+			owners[customer] = i
+			return nil
+		}
+	}
+
+	leave := engine.SubscribeGroup("billing", "invoice.created", member(0))
+	engine.SubscribeGroup("billing", "invoice.created", member(1))
+	engine.SubscribeGroup("billing", "invoice.created", member(2))
+
+	for i := range 30 {
+		engine.Submit("invoice.created", fmt.Sprintf("customer-%d", i%10))
+	}
+
+	// Only the customers of the leaving member may move
+	leave()
+	for customer, owner := range owners {
+		if owner == 0 {
+			delete(owners, customer)
+		}
+	}
+	for i := range 10 {
+		engine.Submit("invoice.created", fmt.Sprintf("customer-%d", i))
+	}
+}
+
+func TestRemoteSubscribeGroup(t *testing.T) {
+	server := beacon.New()
+	conn := startRemote(t, server)
+
+	var mu sync.Mutex
+	received := map[string]int{}
+	done := make(chan struct{}, 10)
+	subscribe := func(name, group string) {
+		client := beacon.New(beacon.WithRemote(conn))
+		handler := func(e beacon.Event) error {
+			mu.Lock()
+			received[name]++
+			mu.Unlock()
+			done <- struct{}{}
+			return nil
+		}
+
+		var unsubscribe func()
+		var err error
+		if group == "" {
+			unsubscribe, err = client.SubscribeRemote("order.*", handler)
+		} else {
+			unsubscribe, err = client.SubscribeRemoteGroup(group, "order.*", handler)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(unsubscribe)
+	}
+	subscribe("a", "mailer")
+	subscribe("b", "mailer")
+	subscribe("audit", "")
+
+	for range 4 {
+		if err := server.SubmitWithContext(context.Background(), "order.placed", "order"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 8 {
+		<-done
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received["a"] != 2 || received["b"] != 2 || received["audit"] != 4 {
+		t.Errorf("unexpected distribution: %v", received)
+	}
+}
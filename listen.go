@@ -0,0 +1,31 @@
+package beacon
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ListenUnix listens on a Unix domain socket for the event service.
+// A socket file left behind by a previous process is removed first.
+func ListenUnix(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+// DialUnix creates a client connection to an event service listening on a Unix domain socket.
+// Unless other credentials are passed in opts, the connection is not encrypted.
+func DialUnix(path string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts = append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts...)
+	return grpc.NewClient("unix://"+path, opts...)
+}
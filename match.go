@@ -0,0 +1,10 @@
+package beacon
+
+import "path"
+
+// matchEventName reports whether an event name matches a pattern using the syntax of path.Match.
+// Malformed patterns match nothing.
+func matchEventName(pattern, eventName string) bool {
+	matched, err := path.Match(pattern, eventName)
+	return err == nil && matched
+}
@@ -0,0 +1,60 @@
+package beacon
+
+import (
+	"sync"
+)
+
+// parallelHandlers configures concurrent handlers for events whose names match a pattern.
+type parallelHandlers struct {
+	pattern        string
+	maxConcurrency int
+}
+
+// WithParallelHandlers runs the handlers of events whose names match the pattern
+// concurrently, at most maxConcurrency at a time. All of them run even if some fail, and
+// their errors are collected in the Result. Handlers subscribed with Sequential still run
+// on their own, in subscription order.
+func WithParallelHandlers(pattern string, maxConcurrency int) Option {
+	return func(engine *Engine) {
+		engine.parallel = append(engine.parallel, parallelHandlers{pattern: pattern, maxConcurrency: maxConcurrency})
+	}
+}
+
+// parallelism returns how many handlers of an event may run concurrently.
+func (s *Engine) parallelism(eventName string) int {
+	for _, p := range s.parallel {
+		if matchEventName(p.pattern, eventName) {
+			return p.maxConcurrency
+		}
+	}
+	return 1
+}
+
+// runParallel runs handlers concurrently, at most limit at a time, and returns their errors
+// ordered by index. The first handler has the given index in the subscription order.
+func runParallel(event Event, subs []*subscription, index, limit int) []*HandlerError {
+	errs := make([]error, len(subs))
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i, sub := range subs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = sub.call(event, index+i)
+		}()
+	}
+	wg.Wait()
+
+	var handlerErrs []*HandlerError
+	for i, err := range errs {
+		if err != nil {
+			handlerErrs = append(handlerErrs, &HandlerError{EventName: event.Name, Index: index + i, Err: err})
+		}
+	}
+	return handlerErrs
+}
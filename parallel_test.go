@@ -0,0 +1,86 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+)
+
+func TestParallelHandlers(t *testing.T) {
+	engine := beacon.New(beacon.WithParallelHandlers("report.*", 4))
+
+	var running, peak atomic.Int32
+	slow := func(e beacon.Event) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+	for range 8 {
+		engine.Subscribe("report.generated", slow)
+	}
+
+	start := time.Now()
+	result, err := engine.SubmitWithResult(context.Background(), "report.generated", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 120*time.Millisecond {
+		t.Errorf("handlers did not run concurrently, took %v", elapsed)
+	}
+	if peak.Load() > 4 {
+		t.Errorf("expected at most 4 concurrent handlers, got %d", peak.Load())
+	}
+	if result.Local.HandlersRun != 8 {
+		t.Errorf("expected 8 handlers to run, got %d", result.Local.HandlersRun)
+	}
+}
+
+func TestParallelHandlersErrors(t *testing.T) {
+	engine := beacon.New(beacon.WithParallelHandlers("*", 8))
+
+	engine.Subscribe("test", func(e beacon.Event) error { return errors.New("first") })
+	engine.Subscribe("test", func(e beacon.Event) error { return nil })
+	engine.Subscribe("test", func(e beacon.Event) error { return errors.New("third") })
+
+	result, err := engine.SubmitWithResult(context.Background(), "test", nil)
+	if err == nil || err.Error() != "first" {
+		t.Errorf("expected error of the first failed handler, got %v", err)
+	}
+	if len(result.Local.Errors) != 2 || result.Local.Errors[0].Index != 0 || result.Local.Errors[1].Index != 2 {
+		t.Errorf("unexpected errors: %+v", result.Local.Errors)
+	}
+}
+
+func TestSequentialHandler(t *testing.T) {
+	engine := beacon.New(beacon.WithParallelHandlers("*", 8))
+
+	var after atomic.Int32
+	engine.Subscribe("test", func(e beacon.Event) error { return nil })
+	engine.Subscribe("test", func(e beacon.Event) error {
+		e.Cancel()
+		return nil
+	}, beacon.Sequential())
+	engine.Subscribe("test", func(e beacon.Event) error {
+		after.Add(1)
+		return nil
+	})
+
+	result, err := engine.SubmitWithResult(context.Background(), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Local.Canceled || result.Local.HandlersRun != 2 || after.Load() != 0 {
+		t.Errorf("expected sequential handler to cancel the event, got %+v", result.Local)
+	}
+}
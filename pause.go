@@ -0,0 +1,118 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrHoldLimit is returned for events submitted while their name is paused once the
+// engine already holds as many events as allowed by WithHoldLimit.
+var ErrHoldLimit = errors.New("too many events held back while paused")
+
+// defaultHoldLimit is the number of events an engine holds back while paused unless
+// configured otherwise.
+const defaultHoldLimit = 10000
+
+// WithHoldLimit configures how many events are held back while their names are paused,
+// 10000 by default. Further paused events fail with ErrHoldLimit. A limit of zero or less
+// holds any number of events.
+func WithHoldLimit(limit int) Option {
+	return func(engine *Engine) {
+		engine.holdLimit = limit
+	}
+}
+
+// Pause holds back events whose names match the pattern instead of running their handlers.
+// Submitting them succeeds with Result.Paused set. Held events are dispatched in order
+// once the pattern is resumed.
+func (s *Engine) Pause(pattern string) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if s.paused == nil {
+		s.paused = make(map[string]bool)
+	}
+	s.paused[pattern] = true
+}
+
+// Resume dispatches the events held back by Pause for the pattern in the background and
+// returns their number. Events also matching another paused pattern stay held. Events
+// failing to dispatch are reported to the function configured with WithErrorHandler, and
+// events that could not be dispatched at all, e.g. because the engine is draining, are
+// recorded as dead letters.
+func (s *Engine) Resume(pattern string) int {
+	s.pauseMu.Lock()
+	delete(s.paused, pattern)
+
+	var released []Event
+	held := s.held[:0:0]
+	for _, event := range s.held {
+		if s.isPaused(event.Name) {
+			held = append(held, event)
+		} else {
+			released = append(released, event)
+		}
+	}
+	s.held = held
+	s.pauseMu.Unlock()
+
+	if len(released) > 0 {
+		go func() {
+			for _, event := range released {
+				result, err := s.fireEvent(event.Name, event)
+				if err == nil {
+					continue
+				}
+				if len(result.Errors) == 0 {
+					// Handler failures are recorded by the dispatch itself
+					s.recordDeadLetter(event, err)
+				}
+				if s.errorHandler != nil {
+					s.errorHandler(event, err)
+				}
+			}
+		}()
+	}
+	return len(released)
+}
+
+// Paused returns the paused patterns.
+func (s *Engine) Paused() []string {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	patterns := make([]string, 0, len(s.paused))
+	for pattern := range s.paused {
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// hold keeps an event back if its name is paused and reports whether it did. It fails
+// with ErrHoldLimit if the name is paused but no more events may be held.
+func (s *Engine) hold(event Event) (bool, error) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if !s.isPaused(event.Name) {
+		return false, nil
+	}
+	if s.holdLimit > 0 && len(s.held) >= s.holdLimit {
+		return false, fmt.Errorf("%w: %s", ErrHoldLimit, event.Name)
+	}
+	event.Context = context.WithoutCancel(event.Context)
+	s.held = append(s.held, event)
+	return true, nil
+}
+
+// isPaused reports whether an event name matches a paused pattern. It must be called
+// with pauseMu held.
+func (s *Engine) isPaused(eventName string) bool {
+	for pattern := range s.paused {
+		if matchEventName(pattern, eventName) {
+			return true
+		}
+	}
+	return false
+}
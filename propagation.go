@@ -0,0 +1,145 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Carrier stores propagated values as string pairs, such as gRPC metadata.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+	Keys() []string
+}
+
+// Propagator carries values of a context across process boundaries.
+type Propagator interface {
+	// Inject writes values from the context into the carrier.
+	Inject(ctx context.Context, carrier Carrier)
+	// Extract returns a copy of the context with the values read from the carrier.
+	Extract(ctx context.Context, carrier Carrier) context.Context
+}
+
+// MetadataCarrier adapts gRPC metadata to the Carrier interface.
+type MetadataCarrier metadata.MD
+
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// HTTPHeaderCarrier adapts HTTP headers to the Carrier interface.
+type HTTPHeaderCarrier http.Header
+
+func (c HTTPHeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HTTPHeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+func (c HTTPHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, strings.ToLower(key))
+	}
+	return keys
+}
+
+// ContextKey is the type of the context keys propagated by DefaultPropagator.
+type ContextKey string
+
+// Context keys of string values propagated by DefaultPropagator.
+const (
+	TenantKey      ContextKey = "tenant"
+	UserKey        ContextKey = "user"
+	RequestIDKey   ContextKey = "request-id"
+	TraceParentKey ContextKey = "traceparent"
+	TraceStateKey  ContextKey = "tracestate"
+)
+
+// DefaultPropagator propagates the tenant, user, request ID and W3C trace context.
+var DefaultPropagator = Propagators(
+	ValuePropagator("beacon-tenant", TenantKey),
+	ValuePropagator("beacon-user", UserKey),
+	ValuePropagator("beacon-request-id", RequestIDKey),
+	ValuePropagator("traceparent", TraceParentKey),
+	ValuePropagator("tracestate", TraceStateKey),
+)
+
+type valuePropagator struct {
+	header string
+	key    any
+}
+
+// ValuePropagator propagates the string value stored under key in a context using the given header.
+func ValuePropagator(header string, key any) Propagator {
+	return valuePropagator{header: strings.ToLower(header), key: key}
+}
+
+func (p valuePropagator) Inject(ctx context.Context, carrier Carrier) {
+	if value, ok := ctx.Value(p.key).(string); ok && value != "" {
+		carrier.Set(p.header, value)
+	}
+}
+
+func (p valuePropagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	if value := carrier.Get(p.header); value != "" {
+		return context.WithValue(ctx, p.key, value)
+	}
+	return ctx
+}
+
+type compositePropagator []Propagator
+
+// Propagators combines multiple propagators into one.
+func Propagators(propagators ...Propagator) Propagator {
+	return compositePropagator(propagators)
+}
+
+func (p compositePropagator) Inject(ctx context.Context, carrier Carrier) {
+	for _, propagator := range p {
+		propagator.Inject(ctx, carrier)
+	}
+}
+
+func (p compositePropagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	for _, propagator := range p {
+		ctx = propagator.Extract(ctx, carrier)
+	}
+	return ctx
+}
+
+// outgoingContext returns a context whose outgoing gRPC metadata carries the header values.
+func outgoingContext(ctx context.Context, header Header) context.Context {
+	if len(header) == 0 {
+		return ctx
+	}
+	md := metadata.MD{}
+	for key, value := range header {
+		md.Set(key, value)
+	}
+	if existing, ok := metadata.FromOutgoingContext(ctx); ok {
+		md = metadata.Join(existing, md)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -0,0 +1,49 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValuePropagator(t *testing.T) {
+	type key struct{}
+	propagator := beacon.ValuePropagator("X-Custom", key{})
+
+	md := metadata.MD{}
+	propagator.Inject(context.WithValue(context.Background(), key{}, "value"), beacon.MetadataCarrier(md))
+
+	if got := md.Get("x-custom"); len(got) != 1 || got[0] != "value" {
+		t.Fatalf("value not injected: %v", md)
+	}
+
+	ctx := propagator.Extract(context.Background(), beacon.MetadataCarrier(md))
+	if got := ctx.Value(key{}); got != "value" {
+		t.Errorf("value not extracted: %v", got)
+	}
+}
+
+func TestRemotePropagation(t *testing.T) {
+	receiver := beacon.New()
+	conn := startRemote(t, receiver, beacon.WithServicePropagator(beacon.DefaultPropagator))
+	sender := beacon.New(beacon.WithRemote(conn), beacon.WithPropagator(beacon.DefaultPropagator))
+
+	var tenant, requestID any
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		tenant = e.Context.Value(beacon.TenantKey)
+		requestID = e.Context.Value(beacon.RequestIDKey)
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), beacon.TenantKey, "acme")
+	ctx = context.WithValue(ctx, beacon.RequestIDKey, "42")
+	if err := sender.SubmitWithContext(ctx, "test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if tenant != "acme" || requestID != "42" {
+		t.Errorf("context values not propagated: tenant=%v, request ID=%v", tenant, requestID)
+	}
+}
@@ -0,0 +1,144 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned for events exceeding a rate limit with the RejectWhenLimited policy.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitPolicy decides what happens to events exceeding a rate limit.
+type RateLimitPolicy int
+
+const (
+	// RejectWhenLimited fails events exceeding the limit with ErrRateLimited.
+	RejectWhenLimited RateLimitPolicy = iota
+	// BlockWhenLimited waits until the event is allowed, or until its context is done.
+	BlockWhenLimited
+	// DropWhenLimited accepts events exceeding the limit without running any handlers.
+	DropWhenLimited
+)
+
+// RateLimit is a token bucket limit for events.
+type RateLimit struct {
+	// PerSecond is the number of events allowed per second on average.
+	PerSecond float64
+	// Burst is the number of events allowed at once. Defaults to 1.
+	Burst  int
+	Policy RateLimitPolicy
+}
+
+// WithRateLimit limits the rate of events submitted to the engine whose names match the
+// pattern. All events matching the pattern share one token bucket. Events are checked
+// against every matching limit.
+func WithRateLimit(pattern string, limit RateLimit) Option {
+	return func(engine *Engine) {
+		engine.rateLimits = append(engine.rateLimits, newRateLimiter(pattern, limit))
+	}
+}
+
+// WithServiceRateLimit limits the rate of events received by the event service whose names
+// match the pattern, like WithRateLimit does for the engine. Rejected events fail with
+// ErrRateLimited, which is reported to clients as ResourceExhausted.
+func WithServiceRateLimit(pattern string, limit RateLimit) ServiceOption {
+	return func(s *server) {
+		s.rateLimits = append(s.rateLimits, newRateLimiter(pattern, limit))
+	}
+}
+
+// rateLimiter applies a rate limit to the events whose names match a pattern.
+type rateLimiter struct {
+	pattern string
+	policy  RateLimitPolicy
+	rate    float64
+	burst   float64
+	clock   Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(pattern string, limit RateLimit) *rateLimiter {
+	burst := float64(max(limit.Burst, 1))
+	return &rateLimiter{
+		pattern: pattern,
+		policy:  limit.Policy,
+		rate:    limit.PerSecond,
+		burst:   burst,
+		tokens:  burst,
+		clock:   RealClock{},
+	}
+}
+
+// take removes a token from the bucket if one is available. Otherwise it returns how
+// long it takes until the next token is available.
+func (l *rateLimiter) take() (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, -1 // No tokens are ever added
+	}
+	return false, time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		ok, delay := l.take()
+		if ok {
+			return nil
+		}
+		if delay < 0 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		ready := make(chan struct{})
+		timer := l.clock.AfterFunc(delay, func() { close(ready) })
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-ready:
+		}
+	}
+}
+
+// applyRateLimits checks an event against the limits matching its name. It reports whether
+// the event was dropped, or returns ErrRateLimited or the context error when it was rejected.
+func applyRateLimits(ctx context.Context, limiters []*rateLimiter, eventName string) (bool, error) {
+	for _, l := range limiters {
+		if !matchEventName(l.pattern, eventName) {
+			continue
+		}
+
+		if l.policy == BlockWhenLimited {
+			if err := l.wait(ctx); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if ok, _ := l.take(); !ok {
+			if l.policy == DropWhenLimited {
+				return true, nil
+			}
+			return false, ErrRateLimited
+		}
+	}
+	return false, nil
+}
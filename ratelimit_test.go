@@ -0,0 +1,94 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+)
+
+func TestRateLimitReject(t *testing.T) {
+	engine := beacon.New(beacon.WithRateLimit("telemetry.*", beacon.RateLimit{PerSecond: 1, Burst: 2}))
+	engine.Subscribe("telemetry.cpu", func(e beacon.Event) error { return nil })
+
+	for range 2 {
+		if err := engine.Submit("telemetry.cpu", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := engine.Submit("telemetry.cpu", nil); !errors.Is(err, beacon.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+	if err := engine.Submit("order.placed", nil); err != nil {
+		t.Errorf("expected events outside the pattern to pass, got %v", err)
+	}
+}
+
+func TestRateLimitDropAndBlock(t *testing.T) {
+	engine := beacon.New(
+		beacon.WithRateLimit("dropped", beacon.RateLimit{PerSecond: 1, Policy: beacon.DropWhenLimited}),
+		beacon.WithRateLimit("blocked", beacon.RateLimit{PerSecond: 50, Policy: beacon.BlockWhenLimited}),
+	)
+
+	runs := map[string]int{}
+	handler := func(e beacon.Event) error {
+		runs[e.Name]++
+		return nil
+	}
+	engine.Subscribe("dropped", handler)
+	engine.Subscribe("blocked", handler)
+
+	start := time.Now()
+	for range 3 {
+		if err := engine.Submit("dropped", nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.Submit("blocked", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if runs["dropped"] != 1 || runs["blocked"] != 3 {
+		t.Errorf("unexpected handler runs: %v", runs)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected blocked submissions to wait, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	engine.Submit("blocked", nil)
+	if err := engine.SubmitWithContext(ctx, "blocked", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected blocked submission to respect the context, got %v", err)
+	}
+}
+
+func TestServiceRateLimit(t *testing.T) {
+	receiver := beacon.New()
+	receiver.Subscribe("telemetry.cpu", func(e beacon.Event) error { return nil })
+	limit := beacon.WithServiceRateLimit("telemetry.*", beacon.RateLimit{PerSecond: 1})
+
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver, limit)))
+	sender.Submit("telemetry.cpu", nil)
+	if err := sender.Submit("telemetry.cpu", nil); !errors.Is(err, beacon.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+
+	server := httptest.NewServer(beacon.NewHTTPHandler(receiver, limit))
+	defer server.Close()
+	body := `{"event_name":"telemetry.cpu","data":null}`
+	http.Post(server.URL, "application/json", strings.NewReader(body))
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status 429, got %d", resp.StatusCode)
+	}
+}
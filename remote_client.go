@@ -2,9 +2,15 @@ package beacon
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
 
 	protoc "github.com/YONEDASH/beacon/internal/protoc"
 	"github.com/bytedance/sonic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -12,24 +18,199 @@ var (
 	sonicApi = sonic.ConfigFastest
 )
 
-// remoteHttpPayload represents the data structure that is sent to the remote server.
-type remoteHttpPayload struct {
-	EventName string `json:"event_name"`
-	Event     Event  `json:"event"`
+// grpcTransport delivers events to a remote server using gRPC.
+type grpcTransport struct {
+	conn   *grpc.ClientConn
+	client protoc.EventServiceClient
+	config *transportConfig
+	clock  Clock
+
+	mu      sync.Mutex
+	cancels []context.CancelFunc
+}
+
+// NewGRPCTransport creates a Transport sending events to the event service behind conn.
+// The connection is owned by the caller and is not closed by the transport.
+func NewGRPCTransport(conn *grpc.ClientConn, opts ...TransportOption) GroupSubscribingTransport {
+	return &grpcTransport{
+		conn:   conn,
+		client: protoc.NewEventServiceClient(conn),
+		config: newTransportConfig(opts),
+		clock:  RealClock{},
+	}
 }
 
-func grpcPostEvent(ctx context.Context, client protoc.EventServiceClient, eventName string, e Event) error {
+// useClock makes the transport timestamp subscription requests by the clock of the engine using it.
+func (t *grpcTransport) useClock(clock Clock) {
+	t.clock = clock
+}
+
+func (t *grpcTransport) Publish(ctx context.Context, e Event, header Header) (*Result, error) {
 	data, err := sonicApi.Marshal(e.Data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	header = maps.Clone(header)
+	if header == nil {
+		header = Header{}
+	}
+	t.config.authorize(header, e, data)
+
+	return grpcPostEvent(outgoingContext(ctx, header), t.client, e, data)
+}
+
+func (t *grpcTransport) Subscribe(pattern string, handler Handler) (func(), error) {
+	return t.subscribe(pattern, "", handler)
+}
+
+func (t *grpcTransport) SubscribeGroup(group, pattern string, handler Handler) (func(), error) {
+	return t.subscribe(pattern, group, handler)
+}
+
+// subscribe streams the remote events matching a pattern to a handler until the returned
+// function is called. When the stream breaks, it is opened again after subscribeRetryDelay.
+func (t *grpcTransport) subscribe(pattern, group string, handler Handler) (func(), error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := t.openStream(ctx, pattern, group)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.cancels = append(t.cancels, cancel)
+	t.mu.Unlock()
+
+	go func() {
+		for {
+			t.receive(ctx, stream, handler)
+
+			retry := make(chan struct{})
+			timer := t.clock.AfterFunc(subscribeRetryDelay, func() { close(retry) })
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-retry:
+			}
+			if reopened, err := t.openStream(ctx, pattern, group); err == nil {
+				stream = reopened
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+// subscribeRetryDelay is the time between attempts to reopen a broken subscription.
+const subscribeRetryDelay = time.Second
+
+// openStream opens a subscription and waits until the server confirmed it.
+func (t *grpcTransport) openStream(ctx context.Context, pattern, group string) (grpc.ServerStreamingClient[protoc.EventMessage], error) {
+	now := t.clock.Now()
+	header := Header{}
+	t.config.authorize(header, Event{Name: pattern, Timestamp: now}, nil)
+
+	stream, err := t.client.Subscribe(outgoingContext(ctx, header), &protoc.SubscribeRequest{
+		Pattern:   pattern,
+		Group:     group,
+		Timestamp: timestamppb.New(now),
+	})
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	if md, err := stream.Header(); err != nil || len(md.Get(subscribedHeader)) == 0 {
+		if err == nil {
+			// The stream ended without headers, its status holds the error
+			_, err = stream.Recv()
+		}
+		return nil, fromStatus(err)
+	}
+	return stream, nil
+}
+
+// receive passes the events of a stream to a handler until the stream ends.
+func (t *grpcTransport) receive(ctx context.Context, stream grpc.ServerStreamingClient[protoc.EventMessage], handler Handler) {
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return
+		}
+
+		var data any
+		if msg.Data != "" {
+			if err := sonicApi.UnmarshalFromString(msg.Data, &data); err != nil {
+				continue // Skip events that cannot be decoded
+			}
+		}
+		handler(Event{
+			ID:        msg.EventId,
+			Name:      msg.EventName,
+			Context:   ctx,
+			Timestamp: msg.Timestamp.AsTime(),
+			Data:      data,
+		})
+	}
+}
+
+func (t *grpcTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, cancel := range t.cancels {
+		cancel()
+	}
+	t.cancels = nil
+	return nil
+}
+
+func (t *grpcTransport) String() string {
+	return t.conn.Target()
+}
+
+func grpcPostEvent(ctx context.Context, client protoc.EventServiceClient, e Event, data []byte) (*Result, error) {
 	req := &protoc.SubmitEventRequest{
-		EventName: eventName,
+		EventName: e.Name,
 		Timestamp: timestamppb.New(e.Timestamp),
 		Data:      string(data),
+		EventId:   e.ID,
+	}
+
+	resp, err := client.SubmitEvent(ctx, req)
+	if err != nil {
+		return resultFromStatus(e.Name, err), fromStatus(err)
+	}
+	return resultFromProto(e.Name, resp), nil
+}
+
+// resultFromProto converts the response of the remote server into a Result.
+func resultFromProto(eventName string, resp *protoc.SubmitEventResponse) *Result {
+	result := &Result{
+		Canceled:    resp.Canceled,
+		Paused:      resp.Paused,
+		HandlersRun: int(resp.HandlersRun),
+	}
+	for _, failure := range resp.Failures {
+		result.Errors = append(result.Errors, &HandlerError{
+			EventName: eventName,
+			Index:     int(failure.Index),
+			Err:       errors.New(failure.Error),
+		})
 	}
+	return result
+}
 
-	_, err = client.SubmitEvent(ctx, req)
-	return err
+// resultFromStatus extracts the Result attached to an error status, if any.
+func resultFromStatus(eventName string, err error) *Result {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, detail := range st.Details() {
+		if resp, ok := detail.(*protoc.SubmitEventResponse); ok {
+			return resultFromProto(eventName, resp)
+		}
+	}
+	return nil
 }
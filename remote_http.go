@@ -0,0 +1,256 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// remoteHttpPayload represents the data structure that is sent to the remote server.
+type remoteHttpPayload struct {
+	EventName string          `json:"event_name"`
+	EventID   string          `json:"event_id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// remoteHttpResponse represents the data structure that is returned by the remote server.
+type remoteHttpResponse struct {
+	Canceled    bool                `json:"canceled"`
+	Paused      bool                `json:"paused,omitempty"`
+	HandlersRun int                 `json:"handlers_run"`
+	Failures    []remoteHttpFailure `json:"failures,omitempty"`
+	Error       *remoteHttpError    `json:"error,omitempty"`
+}
+
+type remoteHttpFailure struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type remoteHttpError struct {
+	Code     codes.Code        `json:"code"`
+	Reason   string            `json:"reason,omitempty"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// httpHandler accepts events as JSON POST requests.
+type httpHandler struct {
+	srv *server
+}
+
+// NewHTTPHandler creates an http.Handler that accepts events as JSON POST requests and
+// runs the handlers of the engine, like the event service registered with RegisterEventService.
+//
+// The request body has the form {"event_name": "...", "data": ...}, optionally with an
+// "event_id" used for deduplication and an RFC 3339 "timestamp".
+func NewHTTPHandler(engine *Engine, opts ...ServiceOption) http.Handler {
+	return &httpHandler{srv: newServer(engine, opts...)}
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := h.submitEvent(w, r)
+	writeHttpResponse(w, result, err)
+}
+
+// defaultMaxRequestSize limits request bodies without WithMaxPayloadSize, like the default
+// receive limit of gRPC servers.
+const defaultMaxRequestSize = 4 << 20
+
+// requestEnvelopeSize is the room left for the fields around the data of an event when
+// limiting request bodies to the size configured with WithMaxPayloadSize.
+const requestEnvelopeSize = 64 << 10
+
+// maxRequestSize returns the largest request body the handler reads.
+func (h *httpHandler) maxRequestSize() int64 {
+	if h.srv.maxPayloadSize > 0 {
+		return int64(h.srv.maxPayloadSize) + requestEnvelopeSize
+	}
+	return defaultMaxRequestSize
+}
+
+func (h *httpHandler) submitEvent(w http.ResponseWriter, r *http.Request) (Result, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxRequestSize()))
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	var payload remoteHttpPayload
+	if err := sonicApi.Unmarshal(body, &payload); err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	in := &IncomingEvent{
+		ID:        payload.EventID,
+		Name:      payload.EventName,
+		Timestamp: payload.Timestamp,
+		Data:      payload.Data,
+		Header:    HTTPHeaderCarrier(r.Header),
+		TLS:       r.TLS,
+	}
+	if in.Timestamp.IsZero() {
+		in.Timestamp = h.srv.engine.clock.Now()
+	}
+
+	return h.srv.receive(r.Context(), in)
+}
+
+// writeHttpResponse writes a result and the error classified like a gRPC status.
+func writeHttpResponse(w http.ResponseWriter, result Result, err error) {
+	resp := remoteHttpResponse{
+		Canceled:    result.Canceled,
+		Paused:      result.Paused,
+		HandlersRun: result.HandlersRun,
+	}
+	for _, handlerErr := range result.Errors {
+		resp.Failures = append(resp.Failures, remoteHttpFailure{Index: handlerErr.Index, Error: handlerErr.Err.Error()})
+	}
+
+	statusCode := http.StatusOK
+	if err != nil {
+		code, reason, metadata := classifyError(err)
+		resp.Error = &remoteHttpError{Code: code, Reason: reason, Message: err.Error(), Metadata: metadata}
+		statusCode = httpStatusFromCode(code)
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			statusCode = http.StatusRequestEntityTooLarge
+		}
+	}
+
+	body, err := sonicApi.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
+// httpStatusFromCode maps the gRPC codes used by the event service to HTTP status codes.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Aborted:
+		return http.StatusConflict
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+// httpTransport delivers events to a remote NewHTTPHandler as JSON POST requests.
+type httpTransport struct {
+	url    string
+	client *http.Client
+	config *transportConfig
+}
+
+// NewHTTPTransport creates a Transport posting events to the NewHTTPHandler at url.
+// If client is nil, http.DefaultClient is used.
+func NewHTTPTransport(url string, client *http.Client, opts ...TransportOption) Transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpTransport{url: url, client: client, config: newTransportConfig(opts)}
+}
+
+func (t *httpTransport) Publish(ctx context.Context, e Event, header Header) (*Result, error) {
+	data, err := sonicApi.Marshal(e.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := sonicApi.Marshal(remoteHttpPayload{
+		EventName: e.Name,
+		EventID:   e.ID,
+		Timestamp: e.Timestamp,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	t.config.authorize(HTTPHeaderCarrier(req.Header), e, data)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload remoteHttpResponse
+	if err := sonicApi.Unmarshal(respBody, &payload); err != nil {
+		return nil, fmt.Errorf("unexpected response from %s: %s", t.url, resp.Status)
+	}
+
+	result := &Result{
+		Canceled:    payload.Canceled,
+		Paused:      payload.Paused,
+		HandlersRun: payload.HandlersRun,
+	}
+	for _, failure := range payload.Failures {
+		result.Errors = append(result.Errors, &HandlerError{EventName: e.Name, Index: failure.Index, Err: errors.New(failure.Error)})
+	}
+
+	if payload.Error != nil {
+		if restored := restoreError(payload.Error.Code, payload.Error.Reason, payload.Error.Message, payload.Error.Metadata); restored != nil {
+			return result, restored
+		}
+		return result, errors.New(payload.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("unexpected response from %s: %s", t.url, resp.Status)
+	}
+	return result, nil
+}
+
+func (t *httpTransport) Close() error {
+	return nil
+}
+
+func (t *httpTransport) String() string {
+	return t.url
+}
@@ -0,0 +1,108 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	receiver := beacon.New()
+	server := httptest.NewServer(beacon.NewHTTPHandler(receiver, beacon.WithServicePropagator(beacon.DefaultPropagator)))
+	defer server.Close()
+
+	sender := beacon.New(
+		beacon.WithTransport(beacon.NewHTTPTransport(server.URL, nil)),
+		beacon.WithPropagator(beacon.DefaultPropagator),
+	)
+
+	var message, tenant any
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		message = e.Data
+		tenant = e.Context.Value(beacon.TenantKey)
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), beacon.TenantKey, "acme")
+	result, err := sender.SubmitWithResult(ctx, "test", "hello world")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message != "hello world" || tenant != "acme" {
+		t.Errorf("unexpected event: message=%v, tenant=%v", message, tenant)
+	}
+	if result.Remote == nil || result.Remote.HandlersRun != 1 {
+		t.Errorf("unexpected remote result: %+v", result.Remote)
+	}
+}
+
+func TestHTTPTransportHandlerError(t *testing.T) {
+	receiver := beacon.New()
+	server := httptest.NewServer(beacon.NewHTTPHandler(receiver))
+	defer server.Close()
+
+	sender := beacon.New(beacon.WithTransport(beacon.NewHTTPTransport(server.URL, nil)))
+
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		return errors.New("some error message")
+	})
+
+	var handlerErr *beacon.HandlerError
+	if err := sender.Submit("test", nil); !errors.As(err, &handlerErr) || handlerErr.Err.Error() != "some error message" {
+		t.Errorf("expected HandlerError, got %v", err)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	receiver := beacon.New()
+	handler := beacon.NewHTTPHandler(receiver)
+
+	var value any
+	receiver.Subscribe("webhook", func(e beacon.Event) error {
+		value = e.Data.(map[string]any)["value"]
+		return nil
+	})
+
+	body := `{"event_name": "webhook", "data": {"value": "test"}}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK || value != "test" {
+		t.Errorf("event not accepted: status=%d, value=%v", rec.Code, value)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"data": 1}`)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for missing event name, got %d", rec.Code)
+	}
+}
+
+func TestHTTPHandlerRequestSize(t *testing.T) {
+	receiver := beacon.New()
+	handler := beacon.NewHTTPHandler(receiver, beacon.WithMaxPayloadSize(16))
+
+	runs := 0
+	receiver.Subscribe("webhook", func(e beacon.Event) error {
+		runs++
+		return nil
+	})
+
+	body := `{"event_name": "webhook", "data": "` + strings.Repeat("x", 128<<10) + `"}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status 413, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "INVALID_PAYLOAD") || runs != 0 {
+		t.Errorf("expected the event to be rejected as invalid payload: %s", rec.Body.String())
+	}
+}
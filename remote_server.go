@@ -2,35 +2,298 @@ package beacon
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sync"
 
 	protoc "github.com/YONEDASH/beacon/internal/protoc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type server struct {
 	protoc.UnimplementedEventServiceServer
-	engine *Engine
+	engine        *Engine
+	dedup         DedupStore
+	rejectUnknown bool
+	propagator    Propagator
+	authenticator Authenticator
+	authorizer    Authorizer
+
+	allowed        []string
+	denied         []string
+	maxPayloadSize int
+	validators     []patternValidator
+	types          map[string]reflect.Type
+	rateLimits     []*rateLimiter
+
+	// drained is closed once the engine starts draining, ending subscription streams
+	drained chan struct{}
+}
+
+// ServiceOption is a functional option for configuring the event service.
+type ServiceOption func(*server)
+
+// WithDeduplication acknowledges events whose ID was already seen without running handlers again.
+func WithDeduplication(store DedupStore) ServiceOption {
+	return func(s *server) {
+		s.dedup = store
+	}
+}
+
+// WithRejectUnknownEvents rejects events that have no subscribed handlers with ErrUnknownEvent.
+func WithRejectUnknownEvents() ServiceOption {
+	return func(s *server) {
+		s.rejectUnknown = true
+	}
+}
+
+// WithServicePropagator restores values propagated by clients into the Event.Context of handlers.
+func WithServicePropagator(propagator Propagator) ServiceOption {
+	return func(s *server) {
+		s.propagator = propagator
+	}
 }
 
 func (s *server) SubmitEvent(ctx context.Context, req *protoc.SubmitEventRequest) (*protoc.SubmitEventResponse, error) {
-	var v any
-	if err := sonicApi.Unmarshal([]byte(req.Data), &v); err != nil {
-		return &protoc.SubmitEventResponse{Success: false}, err
+	result, err := s.submitEvent(ctx, req)
+	resp := resultToProto(result)
+	if err != nil {
+		return nil, toStatus(err, resp)
 	}
+	return resp, nil
+}
 
-	event := Event{
-		Context:   ctx,
+// resultToProto converts a Result into the response sent to the client.
+func resultToProto(result Result) *protoc.SubmitEventResponse {
+	resp := &protoc.SubmitEventResponse{
+		Success:     len(result.Errors) == 0,
+		Canceled:    result.Canceled,
+		Paused:      result.Paused,
+		HandlersRun: int32(result.HandlersRun),
+	}
+	for _, handlerErr := range result.Errors {
+		resp.Failures = append(resp.Failures, &protoc.HandlerFailure{
+			Index: int32(handlerErr.Index),
+			Error: handlerErr.Err.Error(),
+		})
+	}
+	return resp
+}
+
+func (s *server) submitEvent(ctx context.Context, req *protoc.SubmitEventRequest) (Result, error) {
+	return s.receive(ctx, incomingEvent(ctx, &IncomingEvent{
+		ID:        req.EventId,
+		Name:      req.EventName,
 		Timestamp: req.Timestamp.AsTime(),
+		Data:      []byte(req.Data),
+	}))
+}
+
+// incomingEvent adds the metadata and TLS state of a gRPC request to an incoming event.
+func incomingEvent(ctx context.Context, in *IncomingEvent) *IncomingEvent {
+	in.Header = MetadataCarrier{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		in.Header = MetadataCarrier(md)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			in.TLS = &info.State
+		}
+	}
+	return in
+}
+
+// subscribedHeader is sent by the server once a subscription is in place.
+const subscribedHeader = "beacon-subscribed"
+
+// subscribeBuffer is the number of events buffered for each remote subscriber.
+const subscribeBuffer = 64
+
+// Subscribe streams the events matching a pattern to a remote subscriber. Subscribers
+// are authenticated and authorized like clients submitting an event named after the pattern.
+// Each event is then authorized by its name for the subscriber and checked against the
+// allowed and denied event names, so patterns cannot reach events the subscriber may not see.
+// Events are delivered at most once: they are dropped when the subscriber disconnects, and
+// subscribers that do not keep up with their buffer are disconnected. Streams end with
+// ErrDraining once the engine drains, so they do not hold up a graceful stop of the server.
+func (s *server) Subscribe(req *protoc.SubscribeRequest, stream grpc.ServerStreamingServer[protoc.EventMessage]) error {
+	ctx := stream.Context()
+	if req.Pattern == "" {
+		return toStatus(ErrEventNameRequired)
+	}
+	ctx, err := s.admit(ctx, incomingEvent(ctx, &IncomingEvent{Name: req.Pattern, Timestamp: req.Timestamp.AsTime()}))
+	if err != nil {
+		return toStatus(err)
+	}
+	principal, _ := PrincipalFromContext(ctx)
+
+	events := make(chan *protoc.EventMessage, subscribeBuffer)
+	slow := make(chan struct{})
+	var slowOnce sync.Once
+	handler := func(e Event) error {
+		if s.checkName(e.Name) != nil {
+			return nil
+		}
+		if principal != nil && s.authorizer != nil && s.authorizer.Authorize(ctx, principal, e.Name) != nil {
+			return nil
+		}
+
+		data, err := sonicApi.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		select {
+		case events <- &protoc.EventMessage{
+			EventId:   e.ID,
+			EventName: e.Name,
+			Timestamp: timestamppb.New(e.Timestamp),
+			Data:      string(data),
+		}:
+		default:
+			// Never block the dispatch on a subscriber that does not keep up
+			slowOnce.Do(func() { close(slow) })
+		}
+		return nil
+	}
+
+	var unsubscribe func()
+	if req.Group != "" {
+		unsubscribe = s.engine.SubscribeGroup(req.Group, req.Pattern, handler)
+	} else {
+		unsubscribe = s.engine.subscribePattern(req.Pattern, handler)
+	}
+	defer unsubscribe()
+
+	// Let the client know that the subscription is in place
+	if err := stream.SendHeader(metadata.Pairs(subscribedHeader, "true")); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-slow:
+			return status.Error(codes.ResourceExhausted, "subscriber did not keep up with events")
+		case <-s.drained:
+			return toStatus(ErrDraining)
+		case msg := <-events:
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// receive admits, validates, decodes and dispatches an event received from a remote client.
+func (s *server) receive(ctx context.Context, in *IncomingEvent) (Result, error) {
+	return s.receiveWith(ctx, in, s.decode)
+}
+
+// receiveWith is receive with the function decoding the event data, which runs once the
+// event passed the checks of the service.
+func (s *server) receiveWith(ctx context.Context, in *IncomingEvent, decode func(eventName string, data []byte) (any, error)) (Result, error) {
+	ctx, err := s.admit(ctx, in)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := s.validate(in); err != nil {
+		return Result{}, err
+	}
+	if dropped, err := applyRateLimits(ctx, s.rateLimits, in.Name); dropped || err != nil {
+		return Result{}, err
+	}
+
+	v, err := decode(in.Name, in.Data)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return s.dispatch(Event{
+		ID:        in.ID,
+		Name:      in.Name,
+		Context:   ctx,
+		Timestamp: in.Timestamp,
 		Data:      v,
+	})
+}
+
+// admit authenticates and authorizes an event received from a remote client and
+// returns the context for its handlers.
+func (s *server) admit(ctx context.Context, in *IncomingEvent) (context.Context, error) {
+	if s.authenticator != nil {
+		principal, err := s.authenticator.Authenticate(ctx, in)
+		if err != nil {
+			return ctx, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		if s.authorizer != nil {
+			if err := s.authorizer.Authorize(ctx, principal, in.Name); err != nil {
+				return ctx, fmt.Errorf("%w: %v", ErrPermissionDenied, err)
+			}
+		}
+		ctx = context.WithValue(ctx, principalKey{}, principal)
+	}
+
+	if s.propagator != nil {
+		ctx = s.propagator.Extract(ctx, in.Header)
+	}
+	return ctx, nil
+}
+
+// dispatch runs the handlers of the engine for an event received from a remote client.
+func (s *server) dispatch(event Event) (Result, error) {
+	if event.Name == "" {
+		return Result{}, ErrEventNameRequired
+	}
+	if s.rejectUnknown && !s.engine.hasHandlers(event.Name) {
+		return Result{}, fmt.Errorf("%w: %s", ErrUnknownEvent, event.Name)
 	}
 
-	if err := s.engine.fireEvent(req.EventName, event); err != nil {
-		return &protoc.SubmitEventResponse{Success: false}, err
+	dedup := s.dedup != nil && event.ID != ""
+	if dedup && s.dedup.Add(event.ID) {
+		return Result{}, nil
 	}
 
-	return &protoc.SubmitEventResponse{Success: true}, nil
+	result, err := s.engine.fireEvent(event.Name, event)
+	if err != nil {
+		if dedup {
+			// Let a retry of the failed event run the handlers again
+			s.dedup.Remove(event.ID)
+		}
+		if len(result.Errors) > 0 {
+			return result, result.Errors[0]
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// newServer creates the event service of an engine.
+func newServer(engine *Engine, opts ...ServiceOption) *server {
+	srv := &server{engine: engine, drained: make(chan struct{})}
+	engine.onDrain(func() { close(srv.drained) })
+	for _, opt := range opts {
+		opt(srv)
+	}
+	for _, l := range srv.rateLimits {
+		l.clock = engine.clock
+	}
+	if dedup, ok := srv.dedup.(clockUser); ok {
+		dedup.useClock(engine.clock)
+	}
+	if authenticator, ok := srv.authenticator.(clockUser); ok {
+		authenticator.useClock(engine.clock)
+	}
+	return srv
 }
 
-func RegisterEventService(s *grpc.Server, engine *Engine) {
-	protoc.RegisterEventServiceServer(s, &server{engine: engine})
+func RegisterEventService(s *grpc.Server, engine *Engine, opts ...ServiceOption) {
+	protoc.RegisterEventServiceServer(s, newServer(engine, opts...))
 }
@@ -1,50 +1,263 @@
 package beacon_test
 
 import (
-	"net"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+	protoc "github.com/YONEDASH/beacon/internal/protoc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// startRemote serves the event service for receiver and returns a client connection to it.
+func startRemote(t *testing.T, receiver *beacon.Engine, opts ...beacon.ServiceOption) *grpc.ClientConn {
+	t.Helper()
+	return beacontest.ServeBufconn(t, receiver, opts...)
+}
+
 func TestRemote(t *testing.T) {
-	addr := "127.0.0.1:8941"
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver)))
 
-	lis, err := net.Listen("tcp", addr)
-	if err != nil {
+	message := ""
+	handler := func(e beacon.Event) error {
+		message = e.Data.(string)
+		return nil
+	}
+	receiver.Subscribe("test", handler)
+
+	if err := sender.Submit("test", "hello world"); err != nil {
 		t.Fatal(err)
 	}
 
-	s := grpc.NewServer()
+	if message != "hello world" {
+		t.Errorf("unexpected message: %s", message)
+	}
+}
+
+func TestRemoteDeduplication(t *testing.T) {
 	receiver := beacon.New()
-	beacon.RegisterEventService(s, receiver)
+	conn := startRemote(t, receiver, beacon.WithDeduplication(beacon.NewLRUDedupStore(16, 0)))
+	client := protoc.NewEventServiceClient(conn)
 
-	go func() {
-		if err := s.Serve(lis); err != nil {
+	counter := 0
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		counter++
+		return nil
+	})
+
+	req := &protoc.SubmitEventRequest{
+		EventName: "test",
+		Timestamp: timestamppb.Now(),
+		Data:      `"hello world"`,
+		EventId:   "duplicate",
+	}
+	for range 3 {
+		if _, err := client.SubmitEvent(context.Background(), req); err != nil {
 			t.Fatal(err)
 		}
-	}()
+	}
+
+	if counter != 1 {
+		t.Errorf("expected handler to run once, ran %d times", counter)
+	}
+}
+
+func TestRemoteHandlerError(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver)))
+
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		return errors.New("some error message")
+	})
+
+	err := sender.Submit("test", nil)
+	var handlerErr *beacon.HandlerError
+	if !errors.As(err, &handlerErr) {
+		t.Fatalf("expected HandlerError, got %v", err)
+	}
+	if handlerErr.EventName != "test" || handlerErr.Err.Error() != "some error message" {
+		t.Errorf("unexpected handler error: %v", handlerErr)
+	}
+}
+
+func TestRemoteUnknownEvent(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver, beacon.WithRejectUnknownEvents())))
+
+	if err := sender.Submit("does not exist", nil); !errors.Is(err, beacon.ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func TestRemoteResult(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver)))
+
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		e.Cancel()
+		return nil
+	})
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		t.Error("handler was not cancelled")
+		return nil
+	})
+
+	result, err := sender.SubmitWithResult(context.Background(), "test", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	sender := beacon.New(beacon.WithRemote(conn))
 
-	message := ""
-	handler := func(e beacon.Event) error {
-		message = e.Data.(string)
+	if result.Remote == nil || !result.Remote.Canceled || result.Remote.HandlersRun != 1 {
+		t.Errorf("unexpected remote result: %+v", result.Remote)
+	}
+}
+
+func TestRemoteResultHandlerError(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver)))
+
+	receiver.Subscribe("test", func(e beacon.Event) error {
 		return nil
+	})
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		return errors.New("some error message")
+	})
+
+	result, err := sender.SubmitWithResult(context.Background(), "test", nil)
+	if err == nil {
+		t.Fatal("no error received from remote handler")
 	}
-	receiver.Subscribe("test", handler)
 
-	if err := sender.Submit("test", "hello world"); err != nil {
+	if result.Remote == nil || result.Remote.HandlersRun != 2 || len(result.Remote.Errors) != 1 {
+		t.Fatalf("unexpected remote result: %+v", result.Remote)
+	}
+	if failure := result.Remote.Errors[0]; failure.Index != 1 || failure.Err.Error() != "some error message" {
+		t.Errorf("unexpected handler failure: %v", failure)
+	}
+}
+
+func TestRemoteResultHandlerErrorWrapping(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver)))
+
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		return fmt.Errorf("downstream: %w", context.DeadlineExceeded)
+	})
+
+	result, err := sender.SubmitWithResult(context.Background(), "test", nil)
+	var handlerErr *beacon.HandlerError
+	if !errors.As(err, &handlerErr) || handlerErr.Err.Error() != "downstream: context deadline exceeded" {
+		t.Fatalf("expected the handler failure, got %v", err)
+	}
+	if result.Remote == nil || result.Remote.HandlersRun != 1 || len(result.Remote.Errors) != 1 {
+		t.Errorf("unexpected remote result: %+v", result.Remote)
+	}
+}
+
+func TestRemoteRoutes(t *testing.T) {
+	analytics := beacon.New()
+	billing := beacon.New()
+	sender := beacon.New(
+		beacon.WithRemote(startRemote(t, analytics)),
+		beacon.WithRemoteRoute(startRemote(t, billing), "billing.*"),
+	)
+
+	var analyticsEvents, billingEvents int
+	analytics.Subscribe("billing.invoice", func(e beacon.Event) error {
+		analyticsEvents++
+		return nil
+	})
+	analytics.Subscribe("page.view", func(e beacon.Event) error {
+		analyticsEvents++
+		return nil
+	})
+	billing.Subscribe("billing.invoice", func(e beacon.Event) error {
+		billingEvents++
+		return nil
+	})
+	billing.Subscribe("page.view", func(e beacon.Event) error {
+		t.Error("event was routed to the wrong server")
+		return nil
+	})
+
+	result, err := sender.SubmitWithResult(context.Background(), "billing.invoice", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Remotes) != 2 || result.Remote.HandlersRun != 2 {
+		t.Errorf("unexpected remote results: %+v", result.Remotes)
+	}
+	if err := sender.Submit("page.view", nil); err != nil {
 		t.Fatal(err)
 	}
 
-	if message != "hello world" {
-		t.Errorf("unexpected message: %s", message)
+	if analyticsEvents != 2 || billingEvents != 1 {
+		t.Errorf("unexpected deliveries: analytics=%d, billing=%d", analyticsEvents, billingEvents)
+	}
+}
+
+func TestRemoteDeliveryPolicy(t *testing.T) {
+	healthy := beacon.New()
+	failing := beacon.New()
+	healthy.Subscribe("test", func(e beacon.Event) error {
+		return nil
+	})
+	failing.Subscribe("test", func(e beacon.Event) error {
+		return errors.New("some error message")
+	})
+	healthyConn := startRemote(t, healthy)
+	failingConn := startRemote(t, failing)
+
+	all := beacon.New(beacon.WithRemote(healthyConn), beacon.WithRemote(failingConn))
+	var remoteErr *beacon.RemoteError
+	if err := all.Submit("test", nil); !errors.As(err, &remoteErr) || remoteErr.Target != failingConn.Target() {
+		t.Errorf("expected RemoteError for failing server, got %v", err)
+	}
+
+	anyOf := beacon.New(beacon.WithRemote(healthyConn), beacon.WithRemote(failingConn), beacon.WithDeliveryPolicy(beacon.DeliverAny))
+	if err := anyOf.Submit("test", nil); err != nil {
+		t.Errorf("expected success with one healthy server, got %v", err)
+	}
+
+	bestEffort := beacon.New(beacon.WithRemote(failingConn), beacon.WithDeliveryPolicy(beacon.DeliverBestEffort))
+	if err := bestEffort.Submit("test", nil); err != nil {
+		t.Errorf("expected failures to be ignored, got %v", err)
+	}
+}
+
+func TestRemoteSubscribeSlowSubscriber(t *testing.T) {
+	receiver := beacon.New()
+	subscriber := beacon.New(beacon.WithRemote(startRemote(t, receiver)))
+
+	release := make(chan struct{})
+	defer close(release)
+	unsubscribe, err := subscriber.SubscribeRemote("report.*", func(e beacon.Event) error {
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	// Far more data than the subscriber buffer and the flow control windows hold
+	submitted := make(chan struct{})
+	go func() {
+		defer close(submitted)
+		for range 1000 {
+			receiver.Submit("report.generated", strings.Repeat("x", 1<<10))
+		}
+	}()
+	select {
+	case <-submitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatch blocked on a slow subscriber")
 	}
 }
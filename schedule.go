@@ -0,0 +1,279 @@
+package beacon
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"time"
+)
+
+// ScheduledEvent is an event submitted with SubmitAt or SubmitAfter that has not fired yet.
+type ScheduledEvent struct {
+	// ID is the ID the event has once it fires.
+	ID   string
+	Name string
+	// At is the time the event fires.
+	At   time.Time
+	Data any
+
+	engine *Engine
+}
+
+// Cancel stops the event from firing and reports whether it was still scheduled. Events
+// not returned by an engine, such as those listed by a ScheduleStore, cannot be canceled
+// this way; use Engine.CancelScheduled with their ID instead.
+func (e *ScheduledEvent) Cancel() bool {
+	if e.engine == nil {
+		return false
+	}
+	return e.engine.CancelScheduled(e.ID)
+}
+
+// ScheduleStore keeps scheduled events so they survive restarts of the engine.
+type ScheduleStore interface {
+	// Save records a scheduled event.
+	Save(event ScheduledEvent) error
+	// Delete removes a scheduled event whose handlers ran or that was canceled.
+	Delete(id string) error
+	// List returns the scheduled events.
+	List() []ScheduledEvent
+}
+
+// WithScheduleStore keeps events submitted with SubmitAt or SubmitAfter in the store. The
+// events in the store are scheduled again when the engine is created, and are removed from
+// it once their handlers ran. Scheduled events of such an engine only fire after Start, so
+// events that became due while the engine was down reach handlers subscribed after New.
+func WithScheduleStore(store ScheduleStore) Option {
+	return func(engine *Engine) {
+		engine.scheduleStore = store
+	}
+}
+
+// SubmitAfter submits an event once the delay has passed. See SubmitAt.
+func (s *Engine) SubmitAfter(ctx context.Context, delay time.Duration, eventName string, data any) (*ScheduledEvent, error) {
+	return s.SubmitAt(ctx, s.clock.Now().Add(delay), eventName, data)
+}
+
+// SubmitAt submits an event at the given time and returns a handle to cancel it. The
+// event context keeps the values of ctx but is not canceled with it. Like events
+// submitted with SubmitAsync, the event is dispatched without a caller waiting for it
+// and its errors are passed to the handler configured with WithErrorHandler.
+//
+// With WithScheduleStore the event is saved to the store first. Events restored from the
+// store fire with a background context. After Close it fails with ErrClosed.
+func (s *Engine) SubmitAt(ctx context.Context, at time.Time, eventName string, data any) (*ScheduledEvent, error) {
+	if eventName == "" {
+		return nil, ErrEventNameRequired
+	}
+	if s.Draining() {
+		return nil, ErrDraining
+	}
+	if s.scheduleClosed() {
+		return nil, ErrClosed
+	}
+
+	event := ScheduledEvent{ID: newEventID(), Name: eventName, At: at, Data: data}
+	if s.scheduleStore != nil {
+		if err := s.scheduleStore.Save(event); err != nil {
+			return nil, err
+		}
+	}
+	s.schedule(context.WithoutCancel(ctx), event)
+
+	event.engine = s
+	return &event, nil
+}
+
+// Scheduled returns the events that have not fired yet, ordered by the time they fire.
+func (s *Engine) Scheduled() []ScheduledEvent {
+	s.scheduleMu.Lock()
+	events := make([]ScheduledEvent, 0, len(s.scheduleQueue))
+	for _, item := range s.scheduleQueue {
+		event := item.event
+		event.engine = s
+		events = append(events, event)
+	}
+	s.scheduleMu.Unlock()
+
+	slices.SortFunc(events, func(a, b ScheduledEvent) int { return a.At.Compare(b.At) })
+	return events
+}
+
+// CancelScheduled stops a scheduled event from firing and reports whether it was still
+// scheduled.
+func (s *Engine) CancelScheduled(id string) bool {
+	s.scheduleMu.Lock()
+	item, ok := s.scheduled[id]
+	if ok {
+		heap.Remove(&s.scheduleQueue, item.index)
+		delete(s.scheduled, id)
+		s.armSchedule()
+	}
+	s.scheduleMu.Unlock()
+
+	if ok && s.scheduleStore != nil {
+		s.scheduleStore.Delete(id)
+	}
+	return ok
+}
+
+// Start fires the events scheduled on an engine created with WithScheduleStore, including
+// those restored from the store that are already due. It should be called once the handlers
+// are subscribed. Engines without a schedule store fire scheduled events right away.
+func (s *Engine) Start() {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	s.scheduleStarted = true
+	s.armSchedule()
+}
+
+// restoreScheduled schedules the events kept in the schedule store.
+func (s *Engine) restoreScheduled() {
+	for _, event := range s.scheduleStore.List() {
+		s.schedule(context.Background(), event)
+	}
+}
+
+// schedule adds an event to the timer heap.
+func (s *Engine) schedule(ctx context.Context, event ScheduledEvent) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	if s.scheduled == nil {
+		s.scheduled = map[string]*scheduledItem{}
+	}
+	item := &scheduledItem{ctx: ctx, event: event}
+	heap.Push(&s.scheduleQueue, item)
+	s.scheduled[event.ID] = item
+	s.armSchedule()
+}
+
+// armSchedule sets the timer to fire the next scheduled event.
+func (s *Engine) armSchedule() {
+	if s.scheduleStopped || (s.scheduleStore != nil && !s.scheduleStarted) {
+		return
+	}
+	if s.scheduleQueue.Len() == 0 {
+		if s.scheduleTimer != nil {
+			s.scheduleTimer.Stop()
+		}
+		return
+	}
+
+	wait := s.scheduleQueue[0].event.At.Sub(s.clock.Now())
+	if s.scheduleTimer == nil {
+		s.scheduleTimer = s.clock.AfterFunc(wait, s.fireScheduled)
+	} else {
+		s.scheduleTimer.Reset(wait)
+	}
+}
+
+// fireScheduled dispatches the scheduled events that are due.
+func (s *Engine) fireScheduled() {
+	var due []*scheduledItem
+	s.scheduleMu.Lock()
+	now := s.clock.Now()
+	for s.scheduleQueue.Len() > 0 && !s.scheduleQueue[0].event.At.After(now) {
+		item := heap.Pop(&s.scheduleQueue).(*scheduledItem)
+		delete(s.scheduled, item.event.ID)
+		due = append(due, item)
+	}
+	s.armSchedule()
+	s.scheduleMu.Unlock()
+
+	for _, item := range due {
+		event := Event{
+			ID:        item.event.ID,
+			Name:      item.event.Name,
+			Context:   item.ctx,
+			Timestamp: now,
+			Data:      item.event.Data,
+		}
+		var done func()
+		if s.scheduleStore != nil {
+			// Keep the event in the store until its handlers ran, so it fires again after a crash
+			done = func() { s.scheduleStore.Delete(event.ID) }
+		}
+		// While the engine is draining, the event stays in the store for the next start
+		s.submitDetached(event, done)
+	}
+}
+
+// submitDetached dispatches an event fired by the engine itself without a caller waiting
+// for it, passing its errors to the handler configured with WithErrorHandler. Unless it is
+// nil, done is called once the event was dispatched or dropped by a rate limit. It reports
+// false if the engine is draining.
+func (s *Engine) submitDetached(event Event, done func()) bool {
+	if err := s.beginDispatch(); err != nil {
+		return false
+	}
+
+	go func() {
+		if done != nil {
+			defer done()
+		}
+		if dropped, err := applyRateLimits(event.Context, s.rateLimits, event.Name); dropped || err != nil {
+			s.endDispatch()
+			if err != nil && s.errorHandler != nil {
+				s.errorHandler(event, err)
+			}
+			return
+		}
+		s.dispatchAsync(event)
+	}()
+	return true
+}
+
+// scheduleClosed reports whether Close stopped firing scheduled events.
+func (s *Engine) scheduleClosed() bool {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+	return s.scheduleStopped
+}
+
+// stopSchedule stops firing scheduled events. Events kept in the schedule store fire
+// once the next engine using the store is started.
+func (s *Engine) stopSchedule() {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	s.scheduleStopped = true
+	if s.scheduleTimer != nil {
+		s.scheduleTimer.Stop()
+	}
+}
+
+// scheduledItem is an event in the timer heap of the engine.
+type scheduledItem struct {
+	ctx   context.Context
+	event ScheduledEvent
+	index int
+}
+
+// scheduleQueue is a heap of scheduled events ordered by the time they fire.
+type scheduleQueue []*scheduledItem
+
+func (q scheduleQueue) Len() int { return len(q) }
+
+func (q scheduleQueue) Less(i, j int) bool { return q[i].event.At.Before(q[j].event.At) }
+
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduleQueue) Push(x any) {
+	item := x.(*scheduledItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *scheduleQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,123 @@
+package beacon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileScheduleStore is a ScheduleStore keeping the scheduled events in a JSON file, which
+// is rewritten on every change. The data of restored events is decoded from JSON like the
+// data of remote events.
+type FileScheduleStore struct {
+	mu     sync.Mutex
+	path   string
+	events map[string]ScheduledEvent
+}
+
+// scheduledRecord is a scheduled event as stored in the file.
+type scheduledRecord struct {
+	ID   string    `json:"id"`
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+	Data any       `json:"data,omitempty"`
+}
+
+// NewFileScheduleStore creates a ScheduleStore keeping the scheduled events in the file at
+// path, loading the events already in it. The file is created on the first change.
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	store := &FileScheduleStore{path: path, events: map[string]ScheduledEvent{}}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []scheduledRecord
+	if err := sonicApi.Unmarshal(content, &records); err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		store.events[r.ID] = ScheduledEvent{ID: r.ID, Name: r.Name, At: r.At, Data: r.Data}
+	}
+	return store, nil
+}
+
+// Save records a scheduled event.
+func (s *FileScheduleStore) Save(event ScheduledEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.events[event.ID]
+	s.events[event.ID] = event
+	if err := s.write(); err != nil {
+		if existed {
+			s.events[event.ID] = previous
+		} else {
+			delete(s.events, event.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes a scheduled event that fired or was canceled.
+func (s *FileScheduleStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[id]; !ok {
+		return nil
+	}
+	delete(s.events, id)
+	return s.write()
+}
+
+// List returns the scheduled events.
+func (s *FileScheduleStore) List() []ScheduledEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]ScheduledEvent, 0, len(s.events))
+	for _, event := range s.events {
+		events = append(events, event)
+	}
+	return events
+}
+
+// write replaces the file with the current events, going through a temporary file so a
+// crash never leaves a partially written file behind.
+func (s *FileScheduleStore) write() error {
+	records := make([]scheduledRecord, 0, len(s.events))
+	for _, e := range s.events {
+		records = append(records, scheduledRecord{ID: e.ID, Name: e.Name, At: e.At, Data: e.Data})
+	}
+	content, err := sonicApi.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,195 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+func TestSubmitAfter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := beacontest.NewClock(start)
+	engine := beacon.New(beacon.WithClock(clock))
+
+	fired := make(chan beacon.Event, 2)
+	engine.Subscribe("reminder", func(e beacon.Event) error {
+		fired <- e
+		return nil
+	})
+
+	later, err := engine.SubmitAfter(context.Background(), 40*time.Millisecond, "reminder", "later")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.SubmitAfter(context.Background(), 10*time.Millisecond, "reminder", "sooner"); err != nil {
+		t.Fatal(err)
+	}
+	if scheduled := engine.Scheduled(); len(scheduled) != 2 || scheduled[0].Data != "sooner" {
+		t.Errorf("unexpected scheduled events: %+v", scheduled)
+	}
+
+	for _, step := range []struct {
+		advance  time.Duration
+		expected string
+	}{
+		{10 * time.Millisecond, "sooner"},
+		{30 * time.Millisecond, "later"},
+	} {
+		clock.Advance(step.advance - time.Millisecond)
+		if len(engine.Scheduled()) == 0 || engine.Scheduled()[0].Data != step.expected {
+			t.Fatalf("%q fired too early", step.expected)
+		}
+		clock.Advance(time.Millisecond)
+
+		select {
+		case e := <-fired:
+			if e.Data != step.expected || !e.Timestamp.Equal(clock.Now()) {
+				t.Errorf("expected %q to fire at %v, got %v at %v", step.expected, clock.Now(), e.Data, e.Timestamp)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%q did not fire", step.expected)
+		}
+	}
+	if later.Cancel() {
+		t.Error("expected fired event not to be canceled")
+	}
+}
+
+func TestSubmitAtCancel(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+
+	engine.Subscribe("reminder", func(e beacon.Event) error {
+		t.Error("canceled event fired")
+		return nil
+	})
+
+	scheduled, err := engine.SubmitAt(context.Background(), clock.Now().Add(20*time.Millisecond), "reminder", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scheduled.Cancel() {
+		t.Error("expected event to be canceled")
+	}
+
+	clock.Advance(time.Hour)
+	if len(engine.Scheduled()) != 0 || clock.PendingTimers() != 0 {
+		t.Error("expected no scheduled events")
+	}
+	if err := engine.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduledCancel(t *testing.T) {
+	engine := beacon.New()
+	defer engine.Close()
+
+	if _, err := engine.SubmitAfter(context.Background(), time.Hour, "reminder", nil); err != nil {
+		t.Fatal(err)
+	}
+	scheduled := engine.Scheduled()
+	if len(scheduled) != 1 || !scheduled[0].Cancel() {
+		t.Fatalf("expected listed event to be canceled, got %+v", scheduled)
+	}
+	if len(engine.Scheduled()) != 0 {
+		t.Error("expected no scheduled events")
+	}
+
+	// Events not returned by an engine are not canceled
+	if (&beacon.ScheduledEvent{ID: "stored"}).Cancel() {
+		t.Error("expected event without engine not to be canceled")
+	}
+}
+
+func TestSubmitAtClosed(t *testing.T) {
+	engine := beacon.New()
+	engine.Close()
+
+	if _, err := engine.SubmitAfter(context.Background(), time.Minute, "reminder", nil); !errors.Is(err, beacon.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestFileScheduleStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+
+	store, err := beacon.NewFileScheduleStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock), beacon.WithScheduleStore(store))
+	if _, err := engine.SubmitAfter(context.Background(), 30*time.Millisecond, "reminder", map[string]any{"user": "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	engine.Close()
+
+	// A restarted engine picks up the events in the file
+	store, err = beacon.NewFileScheduleStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := beacon.New(beacon.WithClock(clock), beacon.WithScheduleStore(store))
+	defer restarted.Close()
+
+	fired := make(chan beacon.Event, 1)
+	restarted.Subscribe("reminder", func(e beacon.Event) error {
+		fired <- e
+		return nil
+	})
+	restarted.Start()
+	clock.Advance(30 * time.Millisecond)
+
+	select {
+	case e := <-fired:
+		if data, ok := e.Data.(map[string]any); !ok || data["user"] != "ada" {
+			t.Errorf("unexpected data: %v", e.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("restored event did not fire")
+	}
+	waitFor(t, func() bool { return len(store.List()) == 0 })
+}
+
+func TestScheduleStoreOverdue(t *testing.T) {
+	store, err := beacon.NewFileScheduleStore(filepath.Join(t.TempDir(), "schedule.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The event became due while the engine was down
+	if err := store.Save(beacon.ScheduledEvent{ID: "overdue", Name: "invoice.due", At: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := beacon.New(beacon.WithScheduleStore(store))
+	defer engine.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	engine.Subscribe("invoice.due", func(e beacon.Event) error {
+		close(started)
+		<-release
+		return nil
+	})
+	if scheduled := engine.Scheduled(); len(scheduled) != 1 || scheduled[0].ID != "overdue" {
+		t.Fatalf("expected the overdue event to wait for Start, got %+v", scheduled)
+	}
+	engine.Start()
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("overdue event did not fire")
+	}
+	if len(store.List()) != 1 {
+		t.Error("event was deleted from the store before its handlers ran")
+	}
+	close(release)
+	waitFor(t, func() bool { return len(store.List()) == 0 })
+}
@@ -0,0 +1,237 @@
+package beacon
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	protoc "github.com/YONEDASH/beacon/internal/protoc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+// DefaultAddress is the address a Server listens on unless configured otherwise.
+const DefaultAddress = "127.0.0.1:8941"
+
+// ErrServerStarted is returned when a Server is started more than once.
+var ErrServerStarted = errors.New("server already started")
+
+// ServerOption is a functional option for configuring a Server.
+type ServerOption func(*Server)
+
+// WithAddress configures the address the server listens on. Addresses of the form
+// "unix:///path/to/socket" listen on a Unix domain socket.
+func WithAddress(address string) ServerOption {
+	return func(s *Server) {
+		s.address = address
+	}
+}
+
+// WithTLS configures the server to accept TLS connections only.
+func WithTLS(config *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, grpc.Creds(credentials.NewTLS(config)))
+	}
+}
+
+// WithUnaryInterceptors adds interceptors to the unary RPCs of the server.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, grpc.ChainUnaryInterceptor(interceptors...))
+	}
+}
+
+// WithStreamInterceptors adds interceptors to the streaming RPCs of the server.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, grpc.ChainStreamInterceptor(interceptors...))
+	}
+}
+
+// WithMaxMessageSize configures the maximum size in bytes of messages the server receives and sends.
+func WithMaxMessageSize(size int) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, grpc.MaxRecvMsgSize(size), grpc.MaxSendMsgSize(size))
+	}
+}
+
+// WithKeepalive configures the keepalive parameters and enforcement policy of the server.
+func WithKeepalive(params keepalive.ServerParameters, policy keepalive.EnforcementPolicy) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, grpc.KeepaliveParams(params), grpc.KeepaliveEnforcementPolicy(policy))
+	}
+}
+
+// WithServiceOptions configures the event service registered by the server.
+func WithServiceOptions(opts ...ServiceOption) ServerOption {
+	return func(s *Server) {
+		s.serviceOpts = append(s.serviceOpts, opts...)
+	}
+}
+
+// WithReflection registers the gRPC server reflection service, which lets tools like
+// grpcurl discover the services of the server.
+func WithReflection() ServerOption {
+	return func(s *Server) {
+		s.reflection = true
+	}
+}
+
+// WithAdminService registers the admin service of the engine, see RegisterAdminService.
+// Admin calls are authenticated and authorized with the options given to WithServiceOptions.
+func WithAdminService() ServerOption {
+	return func(s *Server) {
+		s.admin = true
+	}
+}
+
+// WithGRPCServerOptions passes options to the underlying gRPC server.
+func WithGRPCServerOptions(opts ...grpc.ServerOption) ServerOption {
+	return func(s *Server) {
+		s.grpcOpts = append(s.grpcOpts, opts...)
+	}
+}
+
+// Server serves the event service of an engine over gRPC and manages its lifecycle.
+type Server struct {
+	engine      *Engine
+	address     string
+	serviceOpts []ServiceOption
+	grpcOpts    []grpc.ServerOption
+	reflection  bool
+	admin       bool
+	grpcServer  *grpc.Server
+	health      *health.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+	serveErr chan error
+}
+
+// NewServer creates a Server for the engine. The event service and the standard gRPC health
+// service are registered right away, so further services can be registered on GRPCServer
+// before the server is started. The health service reports NOT_SERVING once the engine drains.
+func NewServer(engine *Engine, opts ...ServerOption) *Server {
+	s := &Server{
+		engine:  engine,
+		address: DefaultAddress,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.grpcServer = grpc.NewServer(s.grpcOpts...)
+	RegisterEventService(s.grpcServer, engine, s.serviceOpts...)
+	if s.admin {
+		RegisterAdminService(s.grpcServer, engine, s.serviceOpts...)
+	}
+
+	s.health = health.NewServer()
+	s.health.SetServingStatus(protoc.EventService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s.grpcServer, s.health)
+	engine.onDrain(s.health.Shutdown)
+
+	if s.reflection {
+		reflection.Register(s.grpcServer)
+	}
+
+	return s
+}
+
+// GRPCServer returns the underlying gRPC server.
+func (s *Server) GRPCServer() *grpc.Server {
+	return s.grpcServer
+}
+
+// Addr returns the address the server listens on, or nil if it was not started.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		return ErrServerStarted
+	}
+
+	lis, err := listen(s.address)
+	if err != nil {
+		return err
+	}
+
+	s.listener = lis
+	s.serveErr = make(chan error, 1)
+	go func() {
+		s.serveErr <- s.grpcServer.Serve(lis)
+	}()
+
+	return nil
+}
+
+// Serve starts the server and blocks until ctx is done, then stops it gracefully.
+// It returns early if the server fails.
+func (s *Server) Serve(ctx context.Context) error {
+	if err := s.Start(); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-s.serveErr:
+		return err
+	case <-ctx.Done():
+		return s.GracefulStop(context.Background())
+	}
+}
+
+// GracefulStop stops accepting new events, waits for pending RPCs to finish and drains
+// the engine. When ctx is done before that, the server is stopped immediately.
+func (s *Server) GracefulStop(ctx context.Context) error {
+	drained := make(chan error, 1)
+	go func() {
+		drained <- s.engine.Drain(ctx)
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+	return <-drained
+}
+
+// Stop stops the server immediately, closing all connections and pending RPCs.
+func (s *Server) Stop() {
+	s.grpcServer.Stop()
+}
+
+// listen listens on a TCP address or, with the "unix://" prefix, on a Unix domain socket.
+func listen(address string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		return ListenUnix(path)
+	}
+	return net.Listen("tcp", address)
+}
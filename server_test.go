@@ -0,0 +1,198 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+func TestServer(t *testing.T) {
+	receiver := beacon.New()
+	server := beacon.NewServer(receiver, beacon.WithAddress("127.0.0.1:0"))
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	sender := beacon.New(beacon.WithRemote(conn))
+
+	message := ""
+	receiver.Subscribe("test", func(e beacon.Event) error {
+		message = e.Data.(string)
+		return nil
+	})
+
+	if err := sender.Submit("test", "hello world"); err != nil {
+		t.Fatal(err)
+	}
+	if message != "hello world" {
+		t.Errorf("unexpected message: %s", message)
+	}
+
+	if err := server.Start(); !errors.Is(err, beacon.ErrServerStarted) {
+		t.Errorf("expected ErrServerStarted, got %v", err)
+	}
+}
+
+func TestServerGracefulStop(t *testing.T) {
+	receiver := beacon.New()
+	server := beacon.NewServer(receiver, beacon.WithAddress("127.0.0.1:0"))
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	finished := false
+	receiver.Subscribe("slow", func(e beacon.Event) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		finished = true
+		return nil
+	})
+
+	go receiver.Submit("slow", nil)
+	<-started
+
+	if err := server.GracefulStop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !finished {
+		t.Error("server stopped before the handler finished")
+	}
+
+	if err := receiver.Submit("slow", nil); !errors.Is(err, beacon.ErrDraining) {
+		t.Errorf("expected ErrDraining after stop, got %v", err)
+	}
+}
+
+func startServer(t *testing.T, receiver *beacon.Engine, opts ...beacon.ServerOption) *grpc.ClientConn {
+	t.Helper()
+
+	server := beacon.NewServer(receiver, append([]beacon.ServerOption{beacon.WithAddress("127.0.0.1:0")}, opts...)...)
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestServerGracefulStopSubscriber(t *testing.T) {
+	receiver := beacon.New()
+	server := beacon.NewServer(receiver, beacon.WithAddress("127.0.0.1:0"))
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	subscriber := beacon.New(beacon.WithRemote(conn))
+	unsubscribe, err := subscriber.SubscribeRemote("order.*", func(e beacon.Event) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.GracefulStop(ctx); err != nil {
+		t.Fatalf("expected an open subscription not to hold up the stop, got %v", err)
+	}
+}
+
+func TestServerHealth(t *testing.T) {
+	receiver := beacon.New()
+	client := healthpb.NewHealthClient(startServer(t, receiver))
+
+	for _, service := range []string{"", "beacon.EventService"} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("%q: expected SERVING, got %v", service, resp.Status)
+		}
+	}
+
+	if err := receiver.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "beacon.EventService"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING while draining, got %v", resp.Status)
+	}
+}
+
+func TestServerAdminService(t *testing.T) {
+	receiver := beacon.New()
+	receiver.Subscribe("order.placed", func(e beacon.Event) error { return nil })
+	receiver.Subscribe("order.placed", func(e beacon.Event) error { return nil })
+	receiver.Subscribe("user.deleted", func(e beacon.Event) error { return nil })
+
+	conn := startServer(t, receiver, beacon.WithAdminService(), beacon.WithReflection(), beacon.WithServiceOptions(
+		beacon.WithAuthenticator(beacon.BearerTokenAuthenticator(map[string]string{"secret": "operator"})),
+	))
+
+	events, err := beacon.NewAdminClient(conn, beacon.WithBearerToken("secret")).ListEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []beacon.EventInfo{
+		{Name: "order.placed", Handlers: 2},
+		{Name: "user.deleted", Handlers: 1},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	services := map[string]bool{}
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services[service.Name] = true
+	}
+	for _, name := range []string{"beacon.EventService", "beacon.AdminService", "grpc.health.v1.Health"} {
+		if !services[name] {
+			t.Errorf("service %s not listed by reflection", name)
+		}
+	}
+}
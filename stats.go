@@ -0,0 +1,79 @@
+package beacon
+
+import (
+	"sort"
+	"time"
+)
+
+// EventStats counts how the events of a name were dispatched.
+type EventStats struct {
+	Name       string
+	Dispatched uint64
+	// Failed counts events with at least one failed handler.
+	Failed   uint64
+	Canceled uint64
+	// TotalLatency is the time spent running handlers, summed over all events.
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// AverageLatency returns the average time spent running the handlers of an event.
+func (s EventStats) AverageLatency() time.Duration {
+	if s.Dispatched == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Dispatched)
+}
+
+// maxStatsNames limits the number of event names with statistics, since remote clients
+// choose the names of the events they submit.
+const maxStatsNames = 10000
+
+// Stats returns the dispatch statistics of the event names whose handlers ran so far,
+// sorted by name. Only the first 10000 names are tracked.
+func (s *Engine) Stats() []EventStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats := make([]EventStats, 0, len(s.stats))
+	for _, st := range s.stats {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// recordStats adds a dispatched event to the statistics of its name. Events without
+// handlers are not recorded.
+func (s *Engine) recordStats(eventName string, result Result, latency time.Duration) {
+	if result.HandlersRun == 0 {
+		return
+	}
+
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	if s.stats == nil {
+		s.stats = make(map[string]*EventStats)
+	}
+	st, ok := s.stats[eventName]
+	if !ok {
+		if len(s.stats) >= maxStatsNames {
+			return
+		}
+		st = &EventStats{Name: eventName}
+		s.stats[eventName] = st
+	}
+
+	st.Dispatched++
+	if len(result.Errors) > 0 {
+		st.Failed++
+	}
+	if result.Canceled {
+		st.Canceled++
+	}
+	st.TotalLatency += latency
+	st.MaxLatency = max(st.MaxLatency, latency)
+}
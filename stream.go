@@ -0,0 +1,280 @@
+package beacon
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SlowConsumerPolicy decides what happens to stream clients that do not keep up with events.
+type SlowConsumerPolicy int
+
+const (
+	// DisconnectSlowConsumers closes the stream of a client whose buffer is full.
+	// The client may reconnect and resume using the Last-Event-ID header.
+	DisconnectSlowConsumers SlowConsumerPolicy = iota
+	// DropEventsForSlowConsumers skips events for a client while its buffer is full.
+	DropEventsForSlowConsumers
+)
+
+// StreamOption is a functional option for configuring a StreamHandler.
+type StreamOption func(*StreamHandler)
+
+// WithStreamHistory configures how many past events are kept for clients resuming a stream.
+func WithStreamHistory(size int) StreamOption {
+	return func(h *StreamHandler) {
+		h.historySize = size
+	}
+}
+
+// WithStreamBuffer configures how many events are buffered for each client.
+func WithStreamBuffer(size int) StreamOption {
+	return func(h *StreamHandler) {
+		h.bufferSize = size
+	}
+}
+
+// WithSlowConsumerPolicy configures what happens to clients whose buffer is full.
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) StreamOption {
+	return func(h *StreamHandler) {
+		h.slowConsumerPolicy = policy
+	}
+}
+
+// WithStreamHeartbeat configures the interval of comments keeping idle streams open. An
+// interval of zero or less disables them.
+func WithStreamHeartbeat(interval time.Duration) StreamOption {
+	return func(h *StreamHandler) {
+		h.heartbeat = interval
+	}
+}
+
+// StreamHandler is an http.Handler streaming the events of an engine to clients as Server-Sent Events.
+//
+// Clients select events with the "pattern" query parameter, using the syntax of path.Match,
+// and the "event" query parameter for exact names. Both may be repeated; without them all
+// events are streamed. A client reconnecting with the Last-Event-ID header first receives
+// the events it missed, as far as they are still kept in the history.
+type StreamHandler struct {
+	historySize        int
+	bufferSize         int
+	slowConsumerPolicy SlowConsumerPolicy
+	heartbeat          time.Duration
+	clock              Clock
+
+	mu      sync.Mutex
+	seq     uint64
+	history []streamEvent
+	clients map[*streamClient]struct{}
+	stop    func()
+}
+
+// streamEvent is an event encoded for the stream.
+type streamEvent struct {
+	id   uint64
+	name string
+	data []byte
+}
+
+// streamPayload is the JSON data of a streamed event.
+type streamPayload struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+type streamClient struct {
+	events   chan streamEvent
+	filter   streamFilter
+	closed   chan struct{}
+	closeOne sync.Once
+}
+
+func (c *streamClient) close() {
+	c.closeOne.Do(func() { close(c.closed) })
+}
+
+// streamFilter selects the events sent to a client.
+type streamFilter struct {
+	patterns []string
+	names    []string
+}
+
+func (f streamFilter) matches(eventName string) bool {
+	if len(f.patterns) == 0 && len(f.names) == 0 {
+		return true
+	}
+	for _, name := range f.names {
+		if name == eventName {
+			return true
+		}
+	}
+	for _, pattern := range f.patterns {
+		if matchEventName(pattern, eventName) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewStreamHandler creates a StreamHandler for the events fired by an engine.
+// Close must be called to stop observing the engine.
+func NewStreamHandler(engine *Engine, opts ...StreamOption) *StreamHandler {
+	h := &StreamHandler{
+		historySize: 256,
+		bufferSize:  64,
+		heartbeat:   15 * time.Second,
+		clock:       engine.clock,
+		clients:     make(map[*streamClient]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.stop = engine.observe(h.publish)
+	return h
+}
+
+// Close stops observing the engine and disconnects all clients.
+func (h *StreamHandler) Close() {
+	h.stop()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		client.close()
+		delete(h.clients, client)
+	}
+}
+
+// publish encodes an event, records it in the history and hands it to the clients.
+func (h *StreamHandler) publish(e Event) {
+	if strings.ContainsAny(e.Name, "\r\n") {
+		return // Line breaks in the name would inject fields into the stream
+	}
+
+	data, err := sonicApi.Marshal(streamPayload{
+		ID:        e.ID,
+		Name:      e.Name,
+		Timestamp: e.Timestamp,
+		Data:      e.Data,
+	})
+	if err != nil {
+		return // Events that cannot be encoded are not streamed
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	se := streamEvent{id: h.seq, name: e.Name, data: data}
+
+	if h.historySize > 0 {
+		h.history = append(h.history, se)
+		if len(h.history) > h.historySize {
+			h.history = h.history[len(h.history)-h.historySize:]
+		}
+	}
+
+	for client := range h.clients {
+		if !client.filter.matches(se.name) {
+			continue
+		}
+		select {
+		case client.events <- se:
+		default:
+			if h.slowConsumerPolicy == DisconnectSlowConsumers {
+				client.close()
+				delete(h.clients, client)
+			}
+		}
+	}
+}
+
+// register adds a client and returns the events it missed since lastID.
+func (h *StreamHandler) register(client *streamClient, lastID uint64, resume bool) []streamEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[client] = struct{}{}
+
+	if !resume {
+		return nil
+	}
+	var missed []streamEvent
+	for _, se := range h.history {
+		if se.id > lastID && client.filter.matches(se.name) {
+			missed = append(missed, se)
+		}
+	}
+	return missed
+}
+
+func (h *StreamHandler) unregister(client *streamClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, client)
+}
+
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	client := &streamClient{
+		events: make(chan streamEvent, h.bufferSize),
+		filter: streamFilter{patterns: query["pattern"], names: query["event"]},
+		closed: make(chan struct{}),
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	lastID, err := strconv.ParseUint(lastEventID, 10, 64)
+	missed := h.register(client, lastID, err == nil)
+	defer h.unregister(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, se := range missed {
+		writeStreamEvent(w, se)
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticks, stop := tick(h.clock, h.heartbeat)
+		defer stop()
+		heartbeat = ticks
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client.closed:
+			return
+		case se := <-client.events:
+			writeStreamEvent(w, se)
+			flusher.Flush()
+		case <-heartbeat:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, se streamEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", se.id, se.name, se.data)
+}
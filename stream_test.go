@@ -0,0 +1,137 @@
+package beacon_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+	"github.com/YONEDASH/beacon/beacontest"
+)
+
+// readStreamEvents reads the names of count events from a Server-Sent Events stream.
+func readStreamEvents(t *testing.T, resp *http.Response, count int) []string {
+	t.Helper()
+
+	var names []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(names) < count && scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			names = append(names, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestStreamHandler(t *testing.T) {
+	engine := beacon.New()
+	handler := beacon.NewStreamHandler(engine)
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?pattern=order.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+
+	engine.Submit("user.created", nil)
+	engine.Submit("order.created", map[string]int{"id": 42})
+	engine.Submit("order.paid", nil)
+
+	names := readStreamEvents(t, resp, 2)
+	if len(names) != 2 || names[0] != "order.created" || names[1] != "order.paid" {
+		t.Errorf("unexpected events: %v", names)
+	}
+}
+
+func TestStreamHandlerResume(t *testing.T) {
+	engine := beacon.New()
+	handler := beacon.NewStreamHandler(engine)
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	engine.Submit("first", nil)
+	engine.Submit("second", nil)
+	engine.Submit("third", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	names := readStreamEvents(t, resp, 2)
+	if len(names) != 2 || names[0] != "second" || names[1] != "third" {
+		t.Errorf("unexpected resumed events: %v", names)
+	}
+}
+
+func TestStreamHandlerLineBreaks(t *testing.T) {
+	engine := beacon.New()
+	handler := beacon.NewStreamHandler(engine, beacon.WithStreamHeartbeat(0))
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	engine.Submit("order.created\nevent: admin.granted\ndata: {}", nil)
+	engine.Submit("order.paid\r", nil)
+	engine.Submit("order.shipped", nil)
+
+	names := readStreamEvents(t, resp, 1)
+	if len(names) != 1 || names[0] != "order.shipped" {
+		t.Errorf("expected names with line breaks to be skipped, got %v", names)
+	}
+}
+
+func TestStreamHandlerHeartbeat(t *testing.T) {
+	clock := beacontest.NewClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	engine := beacon.New(beacon.WithClock(clock))
+	handler := beacon.NewStreamHandler(engine, beacon.WithStreamHeartbeat(time.Second))
+	defer handler.Close()
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Heartbeats follow the clock of the engine
+	waitFor(t, func() bool { return clock.PendingTimers() == 1 })
+	clock.Advance(time.Second)
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if scanner.Text() == ": heartbeat" {
+			return
+		}
+	}
+	t.Fatalf("no heartbeat received: %v", scanner.Err())
+}
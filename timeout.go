@@ -0,0 +1,53 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// HandlerName names a handler in errors such as HandlerTimeoutError.
+func HandlerName(name string) SubscribeOption {
+	return func(sub *subscription) {
+		sub.name = name
+	}
+}
+
+// HandlerTimeout gives a handler its own deadline, set on the Event.Context it receives.
+// When the handler does not return in time, the event continues as if it failed with a
+// *HandlerTimeoutError, while the handler is left to observe its canceled context.
+func HandlerTimeout(timeout time.Duration) SubscribeOption {
+	return func(sub *subscription) {
+		sub.timeout = timeout
+	}
+}
+
+// invoke runs the handler of a subscription for an event, enforcing its timeout.
+func (sub *subscription) invoke(event Event, index int) error {
+	if sub.timeout <= 0 {
+		return sub.handler(event)
+	}
+
+	parent := event.Context
+	ctx, cancel := context.WithTimeout(parent, sub.timeout)
+	defer cancel()
+	event.Context = ctx
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sub.handler(event)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// Only report a timeout if the handler overran its own deadline, not the caller's
+	if errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		return &HandlerTimeoutError{EventName: event.Name, Index: index, Handler: sub.name, Timeout: sub.timeout}
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/YONEDASH/beacon"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+	engine := beacon.New()
+
+	engine.Subscribe("test", func(e beacon.Event) error {
+		<-e.Context.Done()
+		return e.Context.Err()
+	}, beacon.HandlerTimeout(10*time.Millisecond), beacon.HandlerName("slow"))
+
+	err := engine.Submit("test", nil)
+
+	var timeoutErr *beacon.HandlerTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected HandlerTimeoutError, got %v", err)
+	}
+	if timeoutErr.Handler != "slow" || timeoutErr.Index != 0 || timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("unexpected timeout error: %+v", timeoutErr)
+	}
+}
+
+func TestHandlerTimeoutIgnoringContext(t *testing.T) {
+	engine := beacon.New()
+
+	release := make(chan struct{})
+	defer close(release)
+	engine.Subscribe("test", func(e beacon.Event) error {
+		<-release
+		return nil
+	}, beacon.HandlerTimeout(10*time.Millisecond))
+
+	ran := false
+	engine.Subscribe("test", func(e beacon.Event) error {
+		ran = true
+		return nil
+	})
+
+	start := time.Now()
+	var timeoutErr *beacon.HandlerTimeoutError
+	if err := engine.Submit("test", nil); !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected HandlerTimeoutError, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("submit waited for the handler ignoring its context")
+	}
+	if ran {
+		t.Error("handler after the timed out handler ran")
+	}
+}
+
+func TestHandlerTimeoutCallerDeadline(t *testing.T) {
+	engine := beacon.New()
+	engine.Subscribe("test", func(e beacon.Event) error {
+		<-e.Context.Done()
+		return e.Context.Err()
+	}, beacon.HandlerTimeout(time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := engine.SubmitWithContext(ctx, "test", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline to be reported, got %v", err)
+	}
+}
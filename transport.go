@@ -0,0 +1,173 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Transport delivers events to engines outside of the current process.
+type Transport interface {
+	// Publish delivers an event along with the header values injected by the propagator
+	// and returns the result reported by the receiving side.
+	Publish(ctx context.Context, event Event, header Header) (*Result, error)
+	// Close releases the resources held by the transport.
+	Close() error
+}
+
+// SubscribingTransport is a Transport that can also receive events published on the remote side.
+type SubscribingTransport interface {
+	Transport
+	// Subscribe adds a handler for remote events whose names match a pattern
+	// and returns a function removing it again.
+	Subscribe(pattern string, handler Handler) (func(), error)
+}
+
+// GroupSubscribingTransport is a SubscribingTransport that also supports consumer groups.
+type GroupSubscribingTransport interface {
+	SubscribingTransport
+	// SubscribeGroup adds a handler to a consumer group for remote events whose names
+	// match a pattern and returns a function leaving the group again.
+	SubscribeGroup(group, pattern string, handler Handler) (func(), error)
+}
+
+// Header carries propagated values alongside an event. It implements Carrier.
+type Header map[string]string
+
+func (h Header) Get(key string) string {
+	return h[key]
+}
+
+func (h Header) Set(key, value string) {
+	h[key] = value
+}
+
+func (h Header) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// DeliveryPolicy decides whether a submission succeeds when some remote servers fail.
+type DeliveryPolicy int
+
+const (
+	// DeliverAll requires every remote server to process the event successfully.
+	DeliverAll DeliveryPolicy = iota
+	// DeliverAny requires at least one remote server to process the event successfully.
+	DeliverAny
+	// DeliverBestEffort ignores failures of remote servers.
+	DeliverBestEffort
+)
+
+// RemoteResult describes the outcome of sending an event to one remote server.
+type RemoteResult struct {
+	Target string
+	// Result is the result reported by the server, or nil if it could not be reached.
+	Result *Result
+	Err    error
+}
+
+// RemoteError reports that a remote server failed to process an event.
+type RemoteError struct {
+	Target string
+	Err    error
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("remote %s: %v", e.Target, e.Err)
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.Err
+}
+
+// remote is a transport that receives the events matching its patterns.
+type remote struct {
+	target    string
+	transport Transport
+	patterns  []string
+}
+
+// transportTarget names a transport in results and errors.
+func transportTarget(transport Transport, index int) string {
+	if stringer, ok := transport.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("transport #%d", index)
+}
+
+// accepts returns true if the event should be sent to the remote server.
+func (r remote) accepts(eventName string) bool {
+	if len(r.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range r.patterns {
+		if matchEventName(pattern, eventName) {
+			return true
+		}
+	}
+	return false
+}
+
+// postRemotes sends an event to all matching remote servers concurrently and
+// applies the delivery policy to their outcomes.
+func (s *Engine) postRemotes(ctx context.Context, e Event, submitResult *SubmitResult) error {
+	var targets []remote
+	for _, r := range s.remotes {
+		if r.accepts(e.Name) {
+			targets = append(targets, r)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	header := Header{}
+	if s.propagator != nil {
+		s.propagator.Inject(ctx, header)
+	}
+
+	results := make([]RemoteResult, len(targets))
+	var wg sync.WaitGroup
+	for i, r := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := r.transport.Publish(ctx, e, header)
+			if err != nil {
+				err = &RemoteError{Target: r.target, Err: err}
+			}
+			results[i] = RemoteResult{Target: r.target, Result: result, Err: err}
+		}()
+	}
+	wg.Wait()
+
+	submitResult.Remotes = results
+	var errs []error
+	for _, rr := range results {
+		if rr.Result != nil {
+			if submitResult.Remote == nil {
+				submitResult.Remote = &Result{}
+			}
+			submitResult.Remote.Canceled = submitResult.Remote.Canceled || rr.Result.Canceled
+			submitResult.Remote.Paused = submitResult.Remote.Paused || rr.Result.Paused
+			submitResult.Remote.HandlersRun += rr.Result.HandlersRun
+			submitResult.Remote.Errors = append(submitResult.Remote.Errors, rr.Result.Errors...)
+		}
+		if rr.Err != nil {
+			errs = append(errs, rr.Err)
+		}
+	}
+
+	switch {
+	case len(errs) == 0 || s.deliveryPolicy == DeliverBestEffort:
+		return nil
+	case s.deliveryPolicy == DeliverAny && len(errs) < len(results):
+		return nil
+	}
+	return errors.Join(errs...)
+}
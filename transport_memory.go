@@ -0,0 +1,79 @@
+package beacon
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryTransport delivers events to another engine in the same process.
+type memoryTransport struct {
+	srv *server
+
+	mu           sync.Mutex
+	unsubscribes []func()
+}
+
+// NewMemoryTransport creates a Transport delivering events directly to the target engine,
+// as if it was registered as an event service with the given options. Event data is passed
+// by reference without encoding, which makes the transport useful for tests, unless a type
+// was registered for the event with WithType.
+func NewMemoryTransport(target *Engine, opts ...ServiceOption) GroupSubscribingTransport {
+	return &memoryTransport{srv: newServer(target, opts...)}
+}
+
+func (t *memoryTransport) Publish(ctx context.Context, e Event, header Header) (*Result, error) {
+	in := &IncomingEvent{
+		ID:        e.ID,
+		Name:      e.Name,
+		Timestamp: e.Timestamp,
+		Header:    header,
+	}
+	if t.srv.inspectsData(e.Name) {
+		// Authenticators, validators and registered types inspect the encoded data
+		data, err := sonicApi.Marshal(e.Data)
+		if err != nil {
+			return nil, err
+		}
+		in.Data = data
+	}
+
+	result, err := t.srv.receiveWith(ctx, in, func(eventName string, data []byte) (any, error) {
+		if _, typed := t.srv.types[eventName]; typed {
+			return t.srv.decode(eventName, data)
+		}
+		return e.Data, nil
+	})
+	return &result, err
+}
+
+func (t *memoryTransport) Subscribe(pattern string, handler Handler) (func(), error) {
+	return t.track(t.srv.engine.subscribePattern(pattern, handler)), nil
+}
+
+func (t *memoryTransport) SubscribeGroup(group, pattern string, handler Handler) (func(), error) {
+	return t.track(t.srv.engine.SubscribeGroup(group, pattern, handler)), nil
+}
+
+// track remembers an unsubscribe function to call when the transport is closed.
+func (t *memoryTransport) track(unsubscribe func()) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unsubscribes = append(t.unsubscribes, unsubscribe)
+
+	return unsubscribe
+}
+
+func (t *memoryTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, unsubscribe := range t.unsubscribes {
+		unsubscribe()
+	}
+	t.unsubscribes = nil
+	return nil
+}
+
+func (t *memoryTransport) String() string {
+	return "memory"
+}
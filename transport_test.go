@@ -0,0 +1,93 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+)
+
+func TestMemoryTransport(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithTransport(beacon.NewMemoryTransport(receiver)))
+	defer sender.Close()
+
+	type Counter struct {
+		Count int
+	}
+	receiver.Subscribe("increment", func(e beacon.Event) error {
+		e.Data.(*Counter).Count++
+		return nil
+	})
+
+	ctr := new(Counter)
+	result, err := sender.SubmitWithResult(context.Background(), "increment", ctr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ctr.Count != 1 || result.Remote == nil || result.Remote.HandlersRun != 1 {
+		t.Errorf("event not delivered through transport: count=%d, result=%+v", ctr.Count, result.Remote)
+	}
+	if len(result.Remotes) != 1 || result.Remotes[0].Target != "memory" {
+		t.Errorf("unexpected remote results: %+v", result.Remotes)
+	}
+}
+
+func TestMemoryTransportTypes(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithTransport(beacon.NewMemoryTransport(receiver, beacon.WithType[OrderPlaced]())))
+	defer sender.Close()
+
+	var order OrderPlaced
+	receiver.Subscribe(beacon.Wrap(func(o OrderPlaced) error {
+		order = o
+		return nil
+	}))
+
+	if err := sender.Submit(beacon.AsEvent(OrderPlaced{ID: "42", Quantity: 3})); err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != "42" || order.Quantity != 3 {
+		t.Errorf("unexpected order: %+v", order)
+	}
+
+	name := beacon.EventName(OrderPlaced{})
+	for _, data := range []any{OrderPlaced{ID: "43"}, map[string]any{"bogus": 1}} {
+		if err := sender.Submit(name, data); !errors.Is(err, beacon.ErrInvalidPayload) {
+			t.Errorf("expected ErrInvalidPayload for %v, got %v", data, err)
+		}
+	}
+}
+
+func TestMemoryTransportSubscribe(t *testing.T) {
+	publisher := beacon.New()
+	subscriber := beacon.New(beacon.WithTransport(beacon.NewMemoryTransport(publisher)))
+
+	var names []string
+	unsubscribe, err := subscriber.SubscribeRemote("order.*", func(e beacon.Event) error {
+		names = append(names, e.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publisher.Submit("order.created", nil)
+	publisher.Submit("user.created", nil)
+	unsubscribe()
+	publisher.Submit("order.deleted", nil)
+
+	if len(names) != 1 || names[0] != "order.created" {
+		t.Errorf("unexpected remote events: %v", names)
+	}
+}
+
+func TestSubscribeRemoteUnsupported(t *testing.T) {
+	engine := beacon.New()
+
+	if _, err := engine.SubscribeRemote("*", func(e beacon.Event) error { return nil }); !errors.Is(err, beacon.ErrSubscribeUnsupported) {
+		t.Errorf("expected ErrSubscribeUnsupported, got %v", err)
+	}
+}
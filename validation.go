@@ -0,0 +1,152 @@
+package beacon
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/bytedance/sonic"
+)
+
+// sonicStrict decodes the data of registered event types, rejecting unknown fields.
+var sonicStrict = sonic.Config{DisallowUnknownFields: true}.Froze()
+
+// Validator checks the encoded data of an event received by the event service.
+type Validator interface {
+	Validate(eventName string, data []byte) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(eventName string, data []byte) error
+
+func (f ValidatorFunc) Validate(eventName string, data []byte) error {
+	return f(eventName, data)
+}
+
+// patternValidator is a validator for the events whose names match a pattern.
+type patternValidator struct {
+	pattern   string
+	validator Validator
+}
+
+// WithAllowedEvents accepts only events whose names match one of the patterns.
+// Other events are rejected with ErrEventNotAllowed. It may be given multiple times.
+func WithAllowedEvents(patterns ...string) ServiceOption {
+	return func(s *server) {
+		s.allowed = append(s.allowed, patterns...)
+	}
+}
+
+// WithDeniedEvents rejects events whose names match one of the patterns with
+// ErrEventNotAllowed, even if they are allowed by WithAllowedEvents.
+func WithDeniedEvents(patterns ...string) ServiceOption {
+	return func(s *server) {
+		s.denied = append(s.denied, patterns...)
+	}
+}
+
+// WithMaxPayloadSize rejects events whose encoded data exceeds size bytes with ErrInvalidPayload.
+func WithMaxPayloadSize(size int) ServiceOption {
+	return func(s *server) {
+		s.maxPayloadSize = size
+	}
+}
+
+// WithValidator validates the data of events whose names match the pattern before
+// handlers run. Events failing validation are rejected with ErrInvalidPayload.
+func WithValidator(pattern string, validator Validator) ServiceOption {
+	return func(s *server) {
+		s.validators = append(s.validators, patternValidator{pattern: pattern, validator: validator})
+	}
+}
+
+// WithType registers T as the type of the events named EventName(T). Their data is
+// decoded into a T instead of generic JSON values, so handlers wrapped with Wrap receive
+// remote events as well. Data with unknown fields or mismatched types is rejected with
+// ErrInvalidPayload, as are values whose Validate method fails, if T has one.
+func WithType[T any]() ServiceOption {
+	var empty T
+	return func(s *server) {
+		if s.types == nil {
+			s.types = make(map[string]reflect.Type)
+		}
+		s.types[EventName(empty)] = reflect.TypeOf(empty)
+	}
+}
+
+// inspectsData reports whether the service needs the encoded data of events with the name.
+func (s *server) inspectsData(eventName string) bool {
+	_, typed := s.types[eventName]
+	return typed || s.authenticator != nil || s.maxPayloadSize > 0 || len(s.validators) > 0
+}
+
+// validate checks the name and encoded data of an event against the configured rules.
+func (s *server) validate(in *IncomingEvent) error {
+	if in.Name == "" {
+		return ErrEventNameRequired
+	}
+	if err := s.checkName(in.Name); err != nil {
+		return err
+	}
+
+	if s.maxPayloadSize > 0 && len(in.Data) > s.maxPayloadSize {
+		return fmt.Errorf("%w: data exceeds %d bytes", ErrInvalidPayload, s.maxPayloadSize)
+	}
+	for _, v := range s.validators {
+		if !matchEventName(v.pattern, in.Name) {
+			continue
+		}
+		if err := v.validator.Validate(in.Name, in.Data); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+	}
+	return nil
+}
+
+// checkName rejects event names that are denied or missing from the allow list.
+func (s *server) checkName(eventName string) error {
+	for _, pattern := range s.denied {
+		if matchEventName(pattern, eventName) {
+			return fmt.Errorf("%w: %s", ErrEventNotAllowed, eventName)
+		}
+	}
+	if len(s.allowed) > 0 && !s.allows(eventName) {
+		return fmt.Errorf("%w: %s", ErrEventNotAllowed, eventName)
+	}
+	return nil
+}
+
+func (s *server) allows(eventName string) bool {
+	for _, pattern := range s.allowed {
+		if matchEventName(pattern, eventName) {
+			return true
+		}
+	}
+	return false
+}
+
+// decode decodes the data of an event into its registered type or into generic JSON values.
+func (s *server) decode(eventName string, data []byte) (any, error) {
+	t, ok := s.types[eventName]
+	if !ok {
+		var v any
+		if len(data) > 0 {
+			if err := sonicApi.Unmarshal(data, &v); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+			}
+		}
+		return v, nil
+	}
+
+	ptr := reflect.New(t)
+	if len(data) > 0 {
+		if err := sonicStrict.Unmarshal(data, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+	}
+	if validatable, ok := ptr.Interface().(interface{ Validate() error }); ok {
+		if err := validatable.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+	}
+	return ptr.Elem().Interface(), nil
+}
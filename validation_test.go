@@ -0,0 +1,103 @@
+package beacon_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/YONEDASH/beacon"
+)
+
+type OrderPlaced struct {
+	ID       string `json:"id"`
+	Quantity int    `json:"quantity"`
+}
+
+func (o *OrderPlaced) Validate() error {
+	if o.Quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	return nil
+}
+
+func TestAllowedEvents(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver,
+		beacon.WithAllowedEvents("order.*", "ping"),
+		beacon.WithDeniedEvents("order.internal"),
+	)))
+
+	runs := 0
+	handler := func(e beacon.Event) error {
+		runs++
+		return nil
+	}
+	for _, name := range []string{"order.placed", "ping", "order.internal", "user.deleted"} {
+		receiver.Subscribe(name, handler)
+	}
+
+	for _, name := range []string{"order.placed", "ping"} {
+		if err := sender.Submit(name, nil); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"order.internal", "user.deleted"} {
+		if err := sender.Submit(name, nil); !errors.Is(err, beacon.ErrEventNotAllowed) {
+			t.Errorf("%s: expected ErrEventNotAllowed, got %v", name, err)
+		}
+	}
+	if runs != 2 {
+		t.Errorf("expected 2 handler runs, got %d", runs)
+	}
+}
+
+func TestPayloadValidation(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver,
+		beacon.WithMaxPayloadSize(16),
+		beacon.WithValidator("comment.*", beacon.ValidatorFunc(func(eventName string, data []byte) error {
+			if strings.Contains(string(data), "spam") {
+				return errors.New("spam is not allowed")
+			}
+			return nil
+		})),
+	)))
+	receiver.Subscribe("comment.created", func(e beacon.Event) error { return nil })
+
+	if err := sender.Submit("comment.created", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sender.Submit("comment.created", "buy spam"); !errors.Is(err, beacon.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for rejected data, got %v", err)
+	}
+	if err := sender.Submit("comment.created", strings.Repeat("a", 32)); !errors.Is(err, beacon.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for oversized data, got %v", err)
+	}
+}
+
+func TestTypedRemoteEvents(t *testing.T) {
+	receiver := beacon.New()
+	sender := beacon.New(beacon.WithRemote(startRemote(t, receiver, beacon.WithType[OrderPlaced]())))
+
+	var order OrderPlaced
+	receiver.Subscribe(beacon.Wrap(func(o OrderPlaced) error {
+		order = o
+		return nil
+	}))
+
+	if err := sender.Submit(beacon.AsEvent(OrderPlaced{ID: "42", Quantity: 3})); err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != "42" || order.Quantity != 3 {
+		t.Errorf("unexpected order: %+v", order)
+	}
+
+	if err := sender.Submit(beacon.AsEvent(OrderPlaced{ID: "43"})); !errors.Is(err, beacon.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for invalid order, got %v", err)
+	}
+
+	name := beacon.EventName(OrderPlaced{})
+	if err := sender.Submit(name, map[string]any{"id": "44", "quantity": 1, "note": "?"}); !errors.Is(err, beacon.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for unknown field, got %v", err)
+	}
+}